@@ -19,13 +19,18 @@ func ClearAreaAroundPosition(pos data.Position, radius int, filter data.MonsterF
 	ctx.SetLastAction("ClearAreaAroundPosition")
 
 	return ctx.Char.KillMonsterSequence(func(d game.Data) (data.UnitID, bool) {
+		monstersInRadius := make([]data.Monster, 0)
 		for _, m := range d.Monsters.Enemies(filter) {
 			distanceToTarget := pather.DistanceFromPoint(pos, m.Position)
 			if ctx.Data.AreaData.IsWalkable(m.Position) && distanceToTarget <= radius {
-				return m.UnitID, true
+				monstersInRadius = append(monstersInRadius, m)
 			}
 		}
 
+		if m, found := d.BestTarget(monstersInRadius, ctx.IsImmuneToCharacter); found {
+			return m.UnitID, true
+		}
+
 		return 0, false
 	}, nil)
 }
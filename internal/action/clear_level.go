@@ -3,6 +3,7 @@ package action
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
@@ -11,16 +12,38 @@ import (
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
 
+const (
+	explorationSightRadius = 20
+	exploredRoomCoverage   = 90.0
+	// Dead ends are slow to walk into and back, it's enough having seen most of them to know they are empty
+	seenDeadEndCoverage = 60.0
+)
+
 func ClearCurrentLevel(openChests bool, filter data.MonsterFilter) error {
 	ctx := context.Get()
 	ctx.SetLastAction("ClearCurrentLevel")
 
+	coverage := ctx.PathFinder.NewCoverage()
 	rooms := ctx.PathFinder.OptimizeRoomsTraverseOrder()
 	for _, r := range rooms {
+		coverage.MarkExplored(ctx.Data.PlayerUnit.Position, explorationSightRadius)
+
+		// Already seen while clearing other rooms, if there is nothing pending here we can skip it
+		if coverage.RoomCoverage(r) >= exploredRoomCoverage && !roomHasPendingWork(r, openChests, filter) {
+			continue
+		}
+
+		// Monsters and objects are only loaded close to the player, a dead end not seen yet can't be known to be empty
+		if ctx.PathFinder.IsDeadEnd(r) && coverage.RoomCoverage(r) >= seenDeadEndCoverage && !roomHasPendingWork(r, openChests, filter) {
+			ctx.Logger.Debug("Skipping already seen dead end room without monsters", slog.Any("room", r.GetCenter()))
+			continue
+		}
+
 		err := clearRoom(r, filter)
 		if err != nil {
-			ctx.Logger.Warn("Failed to clear room: %v", err)
+			ctx.Logger.Warn("Failed to clear room", slog.String("error", err.Error()))
 		}
+		coverage.MarkExplored(ctx.Data.PlayerUnit.Position, explorationSightRadius)
 
 		if !openChests {
 			continue
@@ -45,9 +68,32 @@ func ClearCurrentLevel(openChests bool, filter data.MonsterFilter) error {
 		}
 	}
 
+	ctx.Logger.Info(fmt.Sprintf("Level cleared, explored %.2f%% of the area", coverage.Percent()))
+
 	return nil
 }
 
+// roomHasPendingWork returns true if there are monsters alive or chests to be opened inside the room
+func roomHasPendingWork(room data.Room, openChests bool, filter data.MonsterFilter) bool {
+	ctx := context.Get()
+
+	for _, m := range ctx.Data.Monsters.Enemies(filter) {
+		if room.IsInside(m.Position) {
+			return true
+		}
+	}
+
+	if openChests {
+		for _, o := range ctx.Data.Objects {
+			if o.IsChest() && o.Selectable && room.IsInside(o.Position) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func clearRoom(room data.Room, filter data.MonsterFilter) error {
 	ctx := context.Get()
 	ctx.SetLastAction("clearRoom")
@@ -72,13 +118,8 @@ func clearRoom(room data.Room, filter data.MonsterFilter) error {
 			return nil
 		}
 
-		// Check if there are monsters that can summon new monsters, and kill them first
-		targetMonster := monsters[0]
-		for _, m := range monsters {
-			if m.IsMonsterRaiser() {
-				targetMonster = m
-			}
-		}
+		// Monsters able to summon new monsters have the highest weight by default, they will be killed first
+		targetMonster, _ := ctx.Data.BestTarget(monsters, ctx.IsImmuneToCharacter)
 
 		path, _, mPathFound := ctx.PathFinder.GetPath(targetMonster.Position)
 		if mPathFound {
@@ -0,0 +1,174 @@
+package action
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/sharedstash"
+)
+
+// isCraftingBase returns true if the item can be used as base for an enabled crafting recipe and we don't have one
+// already in the stash or the inventory. Item level can not be read, so any magic base is accepted.
+func isCraftingBase(i data.Item) bool {
+	ctx := context.Get()
+
+	hasOtherCrafter := sharedstash.HasOtherCrafter(ctx.CharacterCfg.Username, ctx.Name)
+	if (!ctx.CharacterCfg.CubeRecipes.Enabled && !hasOtherCrafter) || i.Quality != item.QualityMagic {
+		return false
+	}
+
+	enabled := enabledRecipeNames(ctx)
+	// Stashed bases go first, an inventory base is only kept if there is none in the stash
+	owned := append(ctx.Data.Inventory.ByLocation(item.LocationStash, item.LocationSharedStash), ctx.Data.Inventory.ByLocation(item.LocationInventory)...)
+	for _, recipe := range availableCubeRecipes() {
+		if !recipe.PurchaseRequired || !slices.Contains(enabled, recipe.Name) {
+			continue
+		}
+
+		if !slices.Contains(recipe.PurchaseItems, string(i.Name)) {
+			continue
+		}
+
+		// One base per recipe is enough, the rest will be collected once this one is used
+		if base, found := findCraftingBase(recipe, owned); !found || base.UnitID == i.UnitID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findCraftingBase looks for a base item for the recipe, bases matching the pickit rules are never used
+func findCraftingBase(recipe CubeRecipe, items []data.Item) (data.Item, bool) {
+	ctx := context.Get()
+
+	for _, itm := range items {
+		if itm.Quality != recipe.PurchaseQuality || !slices.Contains(recipe.PurchaseItems, string(itm.Name)) {
+			continue
+		}
+
+		if _, result := ctx.CharacterCfg.Runtime.Rules.EvaluateAll(itm); result == nip.RuleResultFullMatch {
+			continue
+		}
+
+		return itm, true
+	}
+
+	return data.Item{}, false
+}
+
+// cubeRecipeOutputs returns the items in the inventory that were not there before cubing
+func cubeRecipeOutputs(itemsBefore, usedItems, itemsAfter []data.Item) []data.Item {
+	outputs := make([]data.Item, 0)
+	for _, itm := range itemsAfter {
+		isSameItem := func(i data.Item) bool { return i.UnitID == itm.UnitID }
+		if slices.ContainsFunc(itemsBefore, isSameItem) || slices.ContainsFunc(usedItems, isSameItem) {
+			continue
+		}
+		outputs = append(outputs, itm)
+	}
+
+	return outputs
+}
+
+func sendCubeRecipeEvents(ctx *context.Status, recipe CubeRecipe, usedItems, outputs []data.Item) {
+	keepers := 0
+	for _, itm := range outputs {
+		if stashIt, _, _ := shouldStashIt(itm, false); stashIt {
+			keepers++
+		}
+	}
+
+	ctx.Logger.Info("Cube recipe transmuted",
+		"recipe", recipe.Name,
+		"inputs", len(usedItems),
+		"outputs", len(outputs),
+		"keepers", keepers,
+	)
+
+	event.Send(event.CubeRecipeTransmuted(
+		event.Text(ctx.Name, fmt.Sprintf("Cube recipe %s transmuted", recipe.Name)),
+		recipe.Name,
+		len(usedItems),
+		outputs,
+		keepers,
+	))
+}
+
+// recipeItemsInStash returns the stashed items that can be used for cubing. Shared stash items are left to the
+// crafter of the account if there is one, and items reserved by other supervisors are skipped.
+func recipeItemsInStash(ctx *context.Status) []data.Item {
+	items := ctx.Data.Inventory.ByLocation(item.LocationStash)
+	if sharedstash.HasOtherCrafter(ctx.CharacterCfg.Username, ctx.Name) {
+		return items
+	}
+
+	for _, itm := range ctx.Data.Inventory.ByLocation(item.LocationSharedStash) {
+		if !sharedstash.IsReserved(ctx.CharacterCfg.Username, ctx.Name, itm) {
+			items = append(items, itm)
+		}
+	}
+
+	return items
+}
+
+// enabledRecipeNames returns the recipes we collect ingredients for, when another character of the account is the
+// crafter we also collect the ingredients for its recipes
+func enabledRecipeNames(ctx *context.Status) []string {
+	names := slices.Clone(ctx.CharacterCfg.CubeRecipes.EnabledRecipes)
+	if !sharedstash.HasOtherCrafter(ctx.CharacterCfg.Username, ctx.Name) {
+		return names
+	}
+
+	if crafterCfg, found := config.Characters[sharedstash.Crafter(ctx.CharacterCfg.Username)]; found {
+		for _, name := range crafterCfg.CubeRecipes.EnabledRecipes {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// updateSharedStashIngredients lets the other supervisors of the account know the recipe ingredients available in
+// the shared stash
+func updateSharedStashIngredients(ctx *context.Status) {
+	if ctx.CharacterCfg.Username == "" {
+		return
+	}
+
+	enabled := enabledRecipeNames(ctx)
+	recipes := slices.DeleteFunc(availableCubeRecipes(), func(r CubeRecipe) bool { return !slices.Contains(enabled, r.Name) })
+
+	ingredients := make([]data.Item, 0)
+	for _, itm := range ctx.Data.Inventory.ByLocation(item.LocationSharedStash) {
+		if slices.ContainsFunc(recipes, func(r CubeRecipe) bool { return isRecipeIngredient(r, itm) }) {
+			ingredients = append(ingredients, itm)
+		}
+	}
+
+	sharedstash.Update(ctx.CharacterCfg.Username, ctx.Name, ingredients)
+}
+
+func isRecipeIngredient(recipe CubeRecipe, i data.Item) bool {
+	if recipe.Definition != nil {
+		return slices.ContainsFunc(recipe.Definition.Inputs, func(in config.CubeRecipeInput) bool { return in.Matches(i) })
+	}
+
+	if recipe.Name == "Reroll GrandCharms" && (i.Name == "GrandCharm" || isPerfectGem(i)) {
+		return true
+	}
+
+	if recipe.PurchaseRequired && i.Quality == recipe.PurchaseQuality && slices.Contains(recipe.PurchaseItems, string(i.Name)) {
+		return true
+	}
+
+	return slices.Contains(recipe.Items, string(i.Name))
+}
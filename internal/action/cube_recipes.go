@@ -1,12 +1,15 @@
 package action
 
 import (
+	"log/slog"
 	"slices"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/item"
 	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/sharedstash"
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
 
@@ -15,6 +18,10 @@ type CubeRecipe struct {
 	Items            []string
 	PurchaseRequired bool
 	PurchaseItems    []string
+	PurchaseQuality  item.Quality
+
+	// User defined recipe, Items are ignored and inputs are matched by name and NIP rules
+	Definition *config.CubeRecipeDefinition
 }
 
 var (
@@ -364,8 +371,17 @@ func CubeRecipes() error {
 		return nil
 	}
 
-	itemsInStash := ctx.Data.Inventory.ByLocation(item.LocationStash, item.LocationSharedStash)
-	for _, recipe := range Recipes {
+	// Let the other characters of the account know what we have in the shared stash, reservations are released once
+	// we are done so they can use the items we didn't
+	updateSharedStashIngredients(ctx)
+	defer sharedstash.Release(ctx.CharacterCfg.Username, ctx.Name)
+
+	if crafter := sharedstash.Crafter(ctx.CharacterCfg.Username); crafter != "" && crafter != ctx.Name {
+		ctx.Logger.Debug("Shared stash items are cubed by the account crafter, using only the personal stash", "crafter", crafter)
+	}
+
+	itemsInStash := recipeItemsInStash(ctx)
+	for _, recipe := range availableCubeRecipes() {
 		// Check if the current recipe is Enabled
 		if !slices.Contains(ctx.CharacterCfg.CubeRecipes.EnabledRecipes, recipe.Name) {
 			// is this really needed ? making huge logs
@@ -378,25 +394,41 @@ func CubeRecipes() error {
 		continueProcessing := true
 		for continueProcessing {
 			if items, hasItems := hasItemsForRecipe(ctx, recipe); hasItems {
+				// Another supervisor may be using the same shared stash items
+				if !sharedstash.Reserve(ctx.CharacterCfg.Username, ctx.Name, items) {
+					ctx.Logger.Debug("Recipe items are reserved by another character, skipping recipe", "recipe", recipe.Name)
+					break
+				}
 
-				// TODO: Check if we have the items in our storage and if not, purchase them, else take the item from the storage
+				// Crafting recipes need a magic base, use the one from the stash if we have it, otherwise gamble it
 				if recipe.PurchaseRequired {
-					err := GambleSingleItem(recipe.PurchaseItems, item.QualityMagic)
-					if err != nil {
-						ctx.Logger.Error("Error gambling item, skipping recipe", "error", err, "recipe", recipe.Name)
-						break
+					base, found := findCraftingBase(recipe, recipeItemsInStash(ctx))
+					if !found {
+						err := GambleSingleItem(recipe.PurchaseItems, recipe.PurchaseQuality)
+						if err != nil {
+							ctx.Logger.Error("Error gambling item, skipping recipe", "error", err, "recipe", recipe.Name)
+							break
+						}
+
+						base = getPurchasedItem(ctx, recipe.PurchaseItems, recipe.PurchaseQuality)
+						if base.Name == "" {
+							ctx.Logger.Debug("Could not find purchased item. Skipping recipe", "recipe", recipe.Name)
+							break
+						}
 					}
 
-					purchasedItem := getPurchasedItem(ctx, recipe.PurchaseItems)
-					if purchasedItem.Name == "" {
-						ctx.Logger.Debug("Could not find purchased item. Skipping recipe", "recipe", recipe.Name)
+					if !sharedstash.Reserve(ctx.CharacterCfg.Username, ctx.Name, []data.Item{base}) {
+						ctx.Logger.Debug("Crafting base is reserved by another character, skipping recipe", "recipe", recipe.Name)
 						break
 					}
 
-					// Add the purchased item the list of items to cube
-					items = append(items, purchasedItem)
+					// Add the base item the list of items to cube
+					items = append(items, base)
 				}
 
+				// Keep track of the inventory before cubing, so we can find the output of the recipe
+				itemsBefore := ctx.Data.Inventory.ByLocation(item.LocationInventory)
+
 				// Add items to the cube and perform the transmutation
 				err := CubeAddItems(items...)
 				if err != nil {
@@ -407,7 +439,14 @@ func CubeRecipes() error {
 				}
 
 				// Get a list of items that are in our inventory
+				ctx.RefreshGameData()
 				itemsInInv := ctx.Data.Inventory.ByLocation(item.LocationInventory)
+				outputs := cubeRecipeOutputs(itemsBefore, items, itemsInInv)
+
+				if recipe.Definition != nil {
+					checkCubeRecipeOutput(ctx, *recipe.Definition, outputs)
+				}
+				sendCubeRecipeEvents(ctx, recipe, items, outputs)
 
 				stashingRequired := false
 				stashingGrandCharm := false
@@ -447,6 +486,9 @@ func CubeRecipes() error {
 								DropInventoryItem(item)
 								utils.Sleep(500)
 							}
+						} else if recipe.PurchaseRequired && slices.ContainsFunc(outputs, func(o data.Item) bool { return o.UnitID == item.UnitID }) {
+							// Crafted items not matching the rules stay in the inventory and will be sold to the vendor
+							ctx.Logger.Debug("Crafted item doesn't match NIP rules, it will be sold", "item", item.Name, "recipe", recipe.Name)
 						} else {
 							DropInventoryItem(item)
 							utils.Sleep(500)
@@ -476,12 +518,16 @@ func CubeRecipes() error {
 func hasItemsForRecipe(ctx *context.Status, recipe CubeRecipe) ([]data.Item, bool) {
 
 	ctx.RefreshGameData()
-	items := ctx.Data.Inventory.ByLocation(item.LocationStash, item.LocationSharedStash)
+	items := recipeItemsInStash(ctx)
 	// Special handling for "Reroll GrandCharms" recipe
 	if recipe.Name == "Reroll GrandCharms" {
 		return hasItemsForGrandCharmReroll(ctx, items)
 	}
 
+	if recipe.Definition != nil {
+		return hasItemsForUserRecipe(*recipe.Definition, items)
+	}
+
 	recipeItems := make(map[string]int)
 	for _, item := range recipe.Items {
 		recipeItems[item]++
@@ -576,14 +622,116 @@ func removeUsedItems(stash []data.Item, usedItems []data.Item) []data.Item {
 	return remainingItems
 }
 
-func getPurchasedItem(ctx *context.Status, purchaseItems []string) data.Item {
+func getPurchasedItem(ctx *context.Status, purchaseItems []string, quality item.Quality) data.Item {
 	itemsInInv := ctx.Data.Inventory.ByLocation(item.LocationInventory)
 	for _, citem := range itemsInInv {
 		for _, pi := range purchaseItems {
-			if string(citem.Name) == pi && citem.Quality == item.QualityMagic {
+			if string(citem.Name) == pi && citem.Quality == quality {
 				return citem
 			}
 		}
 	}
 	return data.Item{}
 }
+
+// availableCubeRecipes returns the built-in recipes followed by the user defined ones, including the ones defined by
+// the crafter of the account
+func availableCubeRecipes() []CubeRecipe {
+	ctx := context.Get()
+
+	recipes := slices.Clone(Recipes)
+	for i := range recipes {
+		// Built-in crafting recipes use magic bases
+		if recipes[i].PurchaseRequired && recipes[i].PurchaseQuality == 0 {
+			recipes[i].PurchaseQuality = item.QualityMagic
+		}
+	}
+
+	definitions := ctx.CharacterCfg.Runtime.CubeRecipes
+	for i := range definitions {
+		recipes = append(recipes, userCubeRecipe(&definitions[i]))
+	}
+
+	// Characters collecting ingredients for the crafter of the account need its recipes too, it may define recipes
+	// this character doesn't have
+	if sharedstash.HasOtherCrafter(ctx.CharacterCfg.Username, ctx.Name) {
+		if crafterCfg, found := config.Characters[sharedstash.Crafter(ctx.CharacterCfg.Username)]; found {
+			crafterDefinitions := crafterCfg.Runtime.CubeRecipes
+			for i := range crafterDefinitions {
+				if !slices.ContainsFunc(recipes, func(r CubeRecipe) bool { return r.Name == crafterDefinitions[i].Name }) {
+					recipes = append(recipes, userCubeRecipe(&crafterDefinitions[i]))
+				}
+			}
+		}
+	}
+
+	return recipes
+}
+
+func userCubeRecipe(definition *config.CubeRecipeDefinition) CubeRecipe {
+	return CubeRecipe{
+		Name:             definition.Name,
+		PurchaseRequired: len(definition.Purchase.Items) > 0,
+		PurchaseItems:    definition.Purchase.Items,
+		PurchaseQuality:  definition.PurchaseQuality(),
+		Definition:       definition,
+	}
+}
+
+func hasItemsForUserRecipe(definition config.CubeRecipeDefinition, items []data.Item) ([]data.Item, bool) {
+	ctx := context.Get()
+
+	itemsForRecipe := make([]data.Item, 0)
+	for _, input := range definition.Inputs {
+		found := 0
+		for _, itm := range items {
+			if found == input.Quantity {
+				break
+			}
+
+			// Same item can not be used twice
+			if slices.ContainsFunc(itemsForRecipe, func(i data.Item) bool { return i.UnitID == itm.UnitID }) {
+				continue
+			}
+
+			// Recipes should not take items we want to keep, runes and gems are only kept to be used in recipes
+			if !isCubeIngredient(itm) {
+				if _, result := ctx.CharacterCfg.Runtime.Rules.EvaluateAll(itm); result == nip.RuleResultFullMatch {
+					continue
+				}
+			}
+
+			if input.Matches(itm) {
+				itemsForRecipe = append(itemsForRecipe, itm)
+				found++
+			}
+		}
+
+		if found < input.Quantity {
+			return nil, false
+		}
+	}
+
+	return itemsForRecipe, true
+}
+
+var cubeIngredientTypes = []string{
+	item.TypeRune, item.TypeAmethyst, item.TypeDiamond, item.TypeEmerald, item.TypeRuby, item.TypeSapphire, item.TypeTopaz,
+	item.TypeSkull,
+}
+
+func isCubeIngredient(itm data.Item) bool {
+	return itm.IsPotion() || slices.Contains(cubeIngredientTypes, itm.Type().Code)
+}
+
+// checkCubeRecipeOutput logs if the transmuted item is not the one expected by the recipe
+func checkCubeRecipeOutput(ctx *context.Status, definition config.CubeRecipeDefinition, outputs []data.Item) {
+	for _, itm := range outputs {
+		if definition.OutputMatches(itm) {
+			ctx.Logger.Info("Cube recipe done", slog.String("recipe", definition.Name), slog.String("item", string(itm.Name)))
+			return
+		}
+	}
+
+	ctx.Logger.Warn("Cube recipe output doesn't match the expected output", slog.String("recipe", definition.Name), slog.String("output", definition.Output))
+}
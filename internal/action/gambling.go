@@ -2,7 +2,9 @@ package action
 
 import (
 	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/item"
@@ -11,6 +13,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/nip"
 	"github.com/hectorgimenez/koolo/internal/action/step"
 	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/town"
 	"github.com/hectorgimenez/koolo/internal/ui"
@@ -23,7 +26,7 @@ func Gamble() error {
 	ctx.SetLastAction("Gamble")
 
 	stashedGold, _ := ctx.Data.PlayerUnit.FindStat(stat.StashGold, 0)
-	if ctx.CharacterCfg.Gambling.Enabled && stashedGold.Value >= 2480000 {
+	if ctx.CharacterCfg.Gambling.Enabled && stashedGold.Value >= ctx.CharacterCfg.Gambling.StartGold {
 		ctx.Logger.Info("Time to gamble! Visiting vendor...")
 
 		vendorNPC := town.GetTownByArea(ctx.Data.PlayerUnit.Area).GamblingNPC()
@@ -145,6 +148,55 @@ func GambleSingleItem(items []string, desiredQuality item.Quality) error {
 	}
 }
 
+// gamblingSession keeps track of the gold spent and items found during a single visit to the gambling vendor
+type gamblingSession struct {
+	startGold      int
+	lastKeptGold   int
+	itemsBought    int
+	itemsKept      int
+	qualities      map[string]int
+	itemBoughtGold int
+	// Vendor prices can not be read, an item not reaching the inventory means we can not afford it or there is no room
+	purchaseFailed bool
+}
+
+func newGamblingSession(gold int) *gamblingSession {
+	return &gamblingSession{
+		startGold:    gold,
+		lastKeptGold: gold,
+		qualities:    make(map[string]int),
+	}
+}
+
+// spent returns the net gold spent in the session, gold from the sold items is already discounted
+func (gs *gamblingSession) spent(gold int) int {
+	return gs.startGold - gold
+}
+
+// stopReason checks the gambling thresholds, returns an empty string if we should keep gambling
+func (gs *gamblingSession) stopReason(gold int) string {
+	ctx := context.Get()
+
+	if gs.purchaseFailed {
+		return "not enough gold or inventory space"
+	}
+
+	if gold < ctx.CharacterCfg.Gambling.StopGold {
+		return "gold below threshold"
+	}
+
+	if ctx.CharacterCfg.Gambling.SessionBudget > 0 && gs.spent(gold) >= ctx.CharacterCfg.Gambling.SessionBudget {
+		return "session budget spent"
+	}
+
+	// Stop-loss, too much gold spent since the last item we kept
+	if ctx.CharacterCfg.Gambling.StopLoss > 0 && gs.lastKeptGold-gold >= ctx.CharacterCfg.Gambling.StopLoss {
+		return "stop-loss reached"
+	}
+
+	return ""
+}
+
 func gambleItems() error {
 	ctx := context.Get()
 	ctx.SetLastAction("gambleItems")
@@ -154,37 +206,49 @@ func gambleItems() error {
 	var currentItemIndex int
 	const maxRefreshAttempts = 11
 
+	session := newGamblingSession(ctx.Data.PlayerUnit.TotalPlayerGold())
+
 	for {
 		ctx.PauseIfNotPriority()
 		ctx.RefreshGameData()
 
-		// Check if we should stop gambling due to low gold
-		if ctx.Data.PlayerUnit.TotalPlayerGold() < 500000 {
-			ctx.Logger.Info("Finished gambling - gold below 500k",
-				slog.Int("currentGold", ctx.Data.PlayerUnit.TotalPlayerGold()))
-			return step.CloseAllMenus()
-		}
+		gold := ctx.Data.PlayerUnit.TotalPlayerGold()
 
 		// Process bought item if we have one
 		if itemBought.Name != "" {
 			// Find the bought item in inventory
+			inInventory := false
 			for _, itm := range ctx.Data.Inventory.ByLocation(item.LocationInventory) {
 				if itm.UnitID == itemBought.UnitID {
 					itemBought = itm
+					inInventory = true
 					ctx.Logger.Debug("Gambled for item", slog.Any("item", itemBought))
 					break
 				}
 			}
+			if !inInventory {
+				session.purchaseFailed = true
+				itemBought = data.Item{}
+				continue
+			}
 
-			// Check if item matches NIP rules
-			if _, result := ctx.Data.CharacterCfg.Runtime.Rules.EvaluateAll(itemBought); result == nip.RuleResultFullMatch {
-				ctx.Logger.Info("Found item matching NIP rules, keeping", slog.Any("item", itemBought))
+			price := session.itemBoughtGold - gold
+			session.itemsBought++
+			session.qualities[itemBought.Quality.ToString()]++
+
+			kept := shouldKeepGambledItem(itemBought)
+			if kept {
+				ctx.Logger.Info("Found item matching gambling rules, keeping", slog.Any("item", itemBought))
+				session.itemsKept++
+				session.lastKeptGold = gold
 			} else {
 				// Filter not pass, selling the item
-				ctx.Logger.Debug("Item doesn't match NIP rules, selling", slog.Any("item", itemBought))
+				ctx.Logger.Debug("Item doesn't match gambling rules, selling", slog.Any("item", itemBought))
 				town.SellItem(itemBought)
 			}
 
+			event.Send(event.ItemGambled(event.Text(ctx.Name, fmt.Sprintf("Gambled %s [%s]", itemBought.Name, itemBought.Quality.ToString())), itemBought, price, kept))
+
 			itemBought = data.Item{} // Reset itemBought after processing
 			refreshAttempts = 0      // Reset refresh counter after successful purchase
 
@@ -193,6 +257,27 @@ func gambleItems() error {
 			continue
 		}
 
+		// Check if we should stop gambling due to low gold, budget or stop-loss
+		if reason := session.stopReason(gold); reason != "" {
+			ctx.Logger.Info("Finished gambling",
+				slog.String("reason", reason),
+				slog.Int("currentGold", gold),
+				slog.Int("spent", session.spent(gold)),
+				slog.Int("itemsBought", session.itemsBought),
+				slog.Int("itemsKept", session.itemsKept),
+			)
+			event.Send(event.GamblingFinished(
+				event.Text(ctx.Name, fmt.Sprintf("Finished gambling: %s", reason)),
+				session.spent(gold),
+				session.itemsBought,
+				session.itemsKept,
+				session.qualities,
+				reason,
+			))
+
+			return step.CloseAllMenus()
+		}
+
 		// Try to find and buy items
 		itemFound := false
 		if len(ctx.Data.CharacterCfg.Gambling.Items) > 0 {
@@ -200,6 +285,7 @@ func gambleItems() error {
 			currentItem := ctx.Data.CharacterCfg.Gambling.Items[currentItemIndex]
 			itm, found := ctx.Data.Inventory.Find(currentItem, item.LocationVendor)
 			if found {
+				session.itemBoughtGold = gold
 				town.BuyItem(itm, 1)
 				itemBought = itm
 				itemFound = true
@@ -241,6 +327,28 @@ func gambleItems() error {
 		}
 	}
 }
+
+// shouldKeepGambledItem evaluates the gambled item against the gambling rules, or the pickit rules if there are no
+// gambling rules. Items are unidentified, so any item with a target quality is also kept to be identified later.
+func shouldKeepGambledItem(i data.Item) bool {
+	ctx := context.Get()
+
+	for _, quality := range ctx.CharacterCfg.Gambling.KeepQualities {
+		if strings.EqualFold(i.Quality.ToString(), quality) {
+			return true
+		}
+	}
+
+	rules := ctx.CharacterCfg.Runtime.GamblingRules
+	if len(rules) == 0 {
+		rules = ctx.CharacterCfg.Runtime.Rules
+	}
+
+	_, result := rules.EvaluateAll(i)
+
+	return result == nip.RuleResultFullMatch
+}
+
 func RefreshGamblingWindow(ctx *context.Status) {
 	if ctx.Data.LegacyGraphics {
 		ctx.HID.Click(game.LeftButton, ui.GambleRefreshButtonXClassic, ui.GambleRefreshButtonYClassic)
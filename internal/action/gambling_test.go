@@ -0,0 +1,64 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/context"
+)
+
+func TestGamblingStopReason(t *testing.T) {
+	ctx := context.NewContext("test")
+	t.Cleanup(ctx.Detach)
+	ctx.CharacterCfg = &config.CharacterCfg{}
+
+	type thresholds struct {
+		stopGold      int
+		sessionBudget int
+		stopLoss      int
+	}
+
+	tests := []struct {
+		name         string
+		thresholds   thresholds
+		lastKeptGold int
+		gold         int
+		expected     string
+	}{
+		{name: "No thresholds", gold: 1000, expected: ""},
+		{name: "Stop gold", thresholds: thresholds{stopGold: 500000}, gold: 499999, expected: "gold below threshold"},
+		{name: "Above stop gold", thresholds: thresholds{stopGold: 500000}, gold: 500000, expected: ""},
+		{name: "Budget spent", thresholds: thresholds{sessionBudget: 1000000}, gold: 1000000, expected: "session budget spent"},
+		{name: "Budget not spent", thresholds: thresholds{sessionBudget: 1000000}, gold: 1500000, expected: ""},
+		{name: "Stop-loss", thresholds: thresholds{stopLoss: 300000}, lastKeptGold: 1500000, gold: 1200000, expected: "stop-loss reached"},
+		{name: "Stop-loss reset by kept item", thresholds: thresholds{stopLoss: 300000}, lastKeptGold: 1300000, gold: 1200000, expected: ""},
+		{name: "Stop gold first", thresholds: thresholds{stopGold: 500000, sessionBudget: 100}, gold: 100, expected: "gold below threshold"},
+	}
+
+	for _, tt := range tests {
+		ctx.CharacterCfg.Gambling.StopGold = tt.thresholds.stopGold
+		ctx.CharacterCfg.Gambling.SessionBudget = tt.thresholds.sessionBudget
+		ctx.CharacterCfg.Gambling.StopLoss = tt.thresholds.stopLoss
+
+		session := newGamblingSession(2000000)
+		if tt.lastKeptGold > 0 {
+			session.lastKeptGold = tt.lastKeptGold
+		}
+
+		if reason := session.stopReason(tt.gold); reason != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, reason)
+		}
+	}
+}
+
+func TestGamblingStopsWhenPurchaseFails(t *testing.T) {
+	ctx := context.NewContext("test")
+	t.Cleanup(ctx.Detach)
+	ctx.CharacterCfg = &config.CharacterCfg{}
+
+	session := newGamblingSession(2000000)
+	session.purchaseFailed = true
+	if reason := session.stopReason(2000000); reason == "" {
+		t.Error("expected to stop gambling when items can not be bought")
+	}
+}
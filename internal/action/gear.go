@@ -0,0 +1,610 @@
+package action
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/ui"
+	"github.com/hectorgimenez/koolo/internal/utils"
+)
+
+const (
+	// Minimum relative improvement over the equipped item, avoids swapping items back and forth for tiny gains
+	minGearUpgradeRatio = 0.05
+	// Vendor prices can not be read, so keep some gold for potions, repairs and merc revives
+	minGoldForGearShopping = 5000
+)
+
+// gearWeights defines how much every point of a stat is worth for a given class, damage and defense are handled
+// apart because they come from the item base and not only from the item stats
+type gearWeights struct {
+	stats   map[stat.ID]float64
+	damage  float64 // Per point of average weapon damage
+	defense float64 // Per point of defense
+}
+
+// defensiveGearStats are worth the same for every class, class tables only add the stats related to how they fight
+var defensiveGearStats = map[stat.ID]float64{
+	stat.FasterHitRecovery: 1,
+	stat.FireResist:        1.5,
+	stat.ColdResist:        1.5,
+	stat.LightningResist:   1.5,
+	stat.PoisonResist:      1,
+	stat.MaxLife:           1,
+	stat.Vitality:          1.5,
+	stat.FasterRunWalk:     1,
+	stat.MagicFind:         0.3,
+	stat.DamageReduced:     2,
+}
+
+func classGearStats(stats map[stat.ID]float64) map[stat.ID]float64 {
+	merged := maps.Clone(defensiveGearStats)
+	maps.Copy(merged, stats)
+
+	return merged
+}
+
+// Elemental caster, mana is the bottleneck while leveling
+var sorceressGearWeights = gearWeights{
+	stats: classGearStats(map[stat.ID]float64{
+		stat.AllSkills:       100,
+		stat.AddClassSkills:  100,
+		stat.AddSkillTab:     60,
+		stat.FasterCastRate:  5,
+		stat.MaxMana:         1,
+		stat.Energy:          1,
+		stat.ManaRecovery:    0.5,
+		stat.PierceCold:      3,
+		stat.PierceFire:      3,
+		stat.PierceLightning: 3,
+		stat.Strength:        0.5,
+		stat.Dexterity:       0.3,
+	}),
+	damage:  0.2,
+	defense: 0.05,
+}
+
+// Summons and curses, poison builds benefit from pierce and poison length
+var necromancerGearWeights = gearWeights{
+	stats: classGearStats(map[stat.ID]float64{
+		stat.AllSkills:      100,
+		stat.AddClassSkills: 100,
+		stat.AddSkillTab:    60,
+		stat.FasterCastRate: 4,
+		stat.MaxMana:        0.5,
+		stat.Energy:         0.5,
+		stat.PiercePoison:   3,
+		stat.PoisonLength:   0.5,
+		stat.Strength:       0.5,
+		stat.Dexterity:      0.3,
+	}),
+	damage:  0.2,
+	defense: 0.05,
+}
+
+// Elemental and summon druids keep their distance, block and a bit of weapon damage help with early levels
+var druidGearWeights = gearWeights{
+	stats: classGearStats(map[stat.ID]float64{
+		stat.AllSkills:      100,
+		stat.AddClassSkills: 100,
+		stat.AddSkillTab:    60,
+		stat.FasterCastRate: 3,
+		stat.MaxMana:        0.5,
+		stat.Energy:         0.5,
+		stat.PierceFire:     2,
+		stat.PierceCold:     2,
+		stat.Strength:       0.5,
+		stat.Dexterity:      0.5,
+	}),
+	damage:  0.5,
+	defense: 0.05,
+}
+
+// Leveling paladins melee with Zeal until Blessed Hammer is available, so both weapon damage and casting matter,
+// they always use a shield
+var paladinGearWeights = gearWeights{
+	stats: classGearStats(map[stat.ID]float64{
+		stat.AllSkills:            80,
+		stat.AddClassSkills:       80,
+		stat.AddSkillTab:          50,
+		stat.FasterCastRate:       3,
+		stat.EnhancedDamage:       0.5,
+		stat.IncreasedAttackSpeed: 1.5,
+		stat.AttackRating:         0.05,
+		stat.LifeSteal:            4,
+		stat.ManaSteal:            3,
+		stat.ChanceToBlock:        1,
+		stat.FasterBlockRate:      0.5,
+		stat.MaxMana:              0.5,
+		stat.Strength:             1,
+		stat.Dexterity:            0.5,
+	}),
+	damage:  1.5,
+	defense: 0.15,
+}
+
+// Pure melee, weapon damage and leech are everything
+var barbarianGearWeights = gearWeights{
+	stats: classGearStats(map[stat.ID]float64{
+		stat.AllSkills:            60,
+		stat.AddClassSkills:       60,
+		stat.AddSkillTab:          40,
+		stat.EnhancedDamage:       1.5,
+		stat.IncreasedAttackSpeed: 2,
+		stat.AttackRating:         0.05,
+		stat.LifeSteal:            5,
+		stat.ManaSteal:            3,
+		stat.CrushingBlow:         2,
+		stat.DeadlyStrike:         2,
+		stat.OpenWounds:           1,
+		stat.Strength:             1,
+		stat.Dexterity:            1,
+	}),
+	damage:  3,
+	defense: 0.1,
+}
+
+// Javelin and bow amazons depend on dexterity and pierce
+var amazonGearWeights = gearWeights{
+	stats: classGearStats(map[stat.ID]float64{
+		stat.AllSkills:            70,
+		stat.AddClassSkills:       70,
+		stat.AddSkillTab:          50,
+		stat.EnhancedDamage:       1,
+		stat.IncreasedAttackSpeed: 2,
+		stat.AttackRating:         0.05,
+		stat.Pierce:               1,
+		stat.PierceLightning:      2,
+		stat.LifeSteal:            4,
+		stat.ManaSteal:            3,
+		stat.DeadlyStrike:         1.5,
+		stat.Strength:             0.5,
+		stat.Dexterity:            1.5,
+	}),
+	damage:  2,
+	defense: 0.1,
+}
+
+// Trap assassins cast more than they hit, martial arts builds still use claws
+var assassinGearWeights = gearWeights{
+	stats: classGearStats(map[stat.ID]float64{
+		stat.AllSkills:            90,
+		stat.AddClassSkills:       90,
+		stat.AddSkillTab:          60,
+		stat.FasterCastRate:       3,
+		stat.IncreasedAttackSpeed: 1.5,
+		stat.EnhancedDamage:       0.5,
+		stat.AttackRating:         0.05,
+		stat.PierceLightning:      2,
+		stat.PierceFire:           2,
+		stat.LifeSteal:            3,
+		stat.MaxMana:              0.5,
+		stat.Strength:             0.5,
+		stat.Dexterity:            1,
+	}),
+	damage:  1,
+	defense: 0.1,
+}
+
+var mercGearWeights = gearWeights{
+	stats: map[stat.ID]float64{
+		stat.AllSkills:            20,
+		stat.EnhancedDamage:       1,
+		stat.IncreasedAttackSpeed: 2,
+		stat.LifeSteal:            6,
+		stat.CrushingBlow:         2,
+		stat.DeadlyStrike:         2,
+		stat.FireResist:           1.5,
+		stat.ColdResist:           1.5,
+		stat.LightningResist:      1.5,
+		stat.PoisonResist:         1,
+		stat.MaxLife:              1,
+		stat.Vitality:             1,
+		stat.Strength:             0.5,
+		stat.Dexterity:            0.5,
+		stat.DamageReduced:        2,
+	},
+	damage:  3,
+	defense: 0.1,
+}
+
+var classGearWeights = map[data.Class]gearWeights{
+	data.Amazon:      amazonGearWeights,
+	data.Sorceress:   sorceressGearWeights,
+	data.Necromancer: necromancerGearWeights,
+	data.Paladin:     paladinGearWeights,
+	data.Barbarian:   barbarianGearWeights,
+	data.Druid:       druidGearWeights,
+	data.Assassin:    assassinGearWeights,
+}
+
+// Item types that can only be used by a single class
+var classSpecificItemTypes = map[string]data.Class{
+	item.TypeAmazonItem:   data.Amazon,
+	item.TypeAmazonBow:    data.Amazon,
+	item.TypeAmazonSpear:  data.Amazon,
+	item.TypeOrb:          data.Sorceress,
+	item.TypeVoodooHeads:  data.Necromancer,
+	item.TypeAuricShields: data.Paladin,
+	item.TypePrimalHelm:   data.Barbarian,
+	item.TypePelt:         data.Druid,
+	item.TypeHandtoHand:   data.Assassin,
+	item.TypeHandtoHand2:  data.Assassin,
+}
+
+var shieldItemTypes = []string{item.TypeShield, item.TypeAuricShields, item.TypeVoodooHeads}
+var rangedItemTypes = []string{item.TypeBow, item.TypeCrossbow, item.TypeAmazonBow}
+
+// gearUpgrade is an item that scores better than the one equipped in the same slot
+type gearUpgrade struct {
+	item     data.Item
+	replaces item.LocationType
+	gain     float64
+}
+
+// autoEquipEnabled returns true for leveling characters with auto equip enabled, farming characters keep the gear
+// chosen by the user
+func autoEquipEnabled() bool {
+	ctx := context.Get()
+
+	_, isLevelingChar := ctx.Char.(context.LevelingCharacter)
+	return isLevelingChar && ctx.CharacterCfg.Game.Leveling.AutoEquip
+}
+
+// AutoEquip equips the inventory items scoring better than the ones currently equipped by the character or the
+// mercenary, replaced items are moved to the inventory and will be sold or stashed as any other item.
+func AutoEquip() error {
+	ctx := context.Get()
+	ctx.SetLastAction("AutoEquip")
+
+	if !autoEquipEnabled() {
+		return nil
+	}
+
+	for _, target := range []item.LocationType{item.LocationEquipped, item.LocationMercenary} {
+		if target == item.LocationMercenary && (!ctx.CharacterCfg.Character.UseMerc || ctx.Data.MercHPPercent() <= 0) {
+			continue
+		}
+
+		// Equipping an item changes the inventory, so we need to evaluate it again after every item
+		for attempt := 0; attempt < 10; attempt++ {
+			upgrade, found := findGearUpgrade(ctx.Data.Inventory.ByLocation(item.LocationInventory), target)
+			if !found {
+				break
+			}
+
+			if err := equipItem(upgrade, target); err != nil {
+				ctx.Logger.Warn("Failed equipping item", slog.String("item", string(upgrade.item.Name)), slog.Any("error", err))
+				break
+			}
+		}
+	}
+
+	return step.CloseAllMenus()
+}
+
+// IsGearUpgrade returns true if the item would be an upgrade for the character or the mercenary, used to pick up
+// items that would be ignored otherwise
+func IsGearUpgrade(i data.Item) bool {
+	ctx := context.Get()
+
+	if !autoEquipEnabled() {
+		return false
+	}
+
+	if _, found := gearUpgradeFor(i, item.LocationEquipped); found {
+		return true
+	}
+
+	if ctx.CharacterCfg.Character.UseMerc {
+		_, found := gearUpgradeFor(i, item.LocationMercenary)
+		return found
+	}
+
+	return false
+}
+
+// buyGearUpgrades checks the vendor items for items better than the equipped ones and buys them, vendor window
+// should be already open.
+func buyGearUpgrades() {
+	ctx := context.Get()
+	ctx.SetLastAction("buyGearUpgrades")
+
+	if !autoEquipEnabled() || ctx.Data.PlayerUnit.TotalPlayerGold() < minGoldForGearShopping {
+		return
+	}
+
+	vendorItems := ctx.Data.Inventory.ByLocation(item.LocationVendor)
+	for _, target := range []item.LocationType{item.LocationEquipped, item.LocationMercenary} {
+		if target == item.LocationMercenary && !ctx.CharacterCfg.Character.UseMerc {
+			continue
+		}
+
+		upgrade, found := findGearUpgrade(vendorItems, target)
+		if !found {
+			continue
+		}
+
+		// Armor and weapon tabs
+		if !buyVendorItem(upgrade.item, 1, 1, 2) {
+			ctx.Logger.Warn("Gear upgrade not found in the vendor tabs", slog.String("item", string(upgrade.item.Name)))
+			continue
+		}
+
+		ctx.Logger.Info("Bought gear upgrade from vendor",
+			slog.String("item", string(upgrade.item.Name)),
+			slog.String("quality", upgrade.item.Quality.ToString()),
+			slog.Float64("gain", upgrade.gain),
+		)
+		vendorItems = slices.DeleteFunc(vendorItems, func(itm data.Item) bool {
+			return itm.UnitID == upgrade.item.UnitID
+		})
+	}
+}
+
+func findGearUpgrade(items []data.Item, target item.LocationType) (gearUpgrade, bool) {
+	best := gearUpgrade{}
+	found := false
+	for _, itm := range items {
+		upgrade, isUpgrade := gearUpgradeFor(itm, target)
+		if isUpgrade && (!found || upgrade.gain > best.gain) {
+			best = upgrade
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// gearUpgradeFor compares the item against the ones equipped in the slots where it fits, and returns the slot with
+// the biggest score gain. For rings that means the worst of both is replaced.
+func gearUpgradeFor(i data.Item, target item.LocationType) (gearUpgrade, bool) {
+	ctx := context.Get()
+
+	if !canEquipItem(i, target) {
+		return gearUpgrade{}, false
+	}
+
+	weights := mercGearWeights
+	if target == item.LocationEquipped {
+		weights = classGearWeights[ctx.Data.PlayerUnit.Class]
+	}
+
+	equipped := equippedItems(target)
+	score := gearScore(i, weights)
+
+	best := gearUpgrade{}
+	found := false
+	for _, slot := range i.Type().BodyLocs {
+		current, isEquipped := equipped[slot]
+		if !canReplaceItemInSlot(i, slot, isEquipped, current, equipped, target) {
+			continue
+		}
+
+		currentScore := 0.0
+		if isEquipped {
+			currentScore = gearScore(current, weights)
+		}
+
+		gain := score - currentScore
+		if gain <= 0 || (isEquipped && gain < currentScore*minGearUpgradeRatio) {
+			continue
+		}
+
+		if !found || gain > best.gain {
+			best = gearUpgrade{item: i, replaces: slot, gain: gain}
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+func canEquipItem(i data.Item, target item.LocationType) bool {
+	ctx := context.Get()
+
+	if len(i.Type().BodyLocs) == 0 || i.IsFromQuest() {
+		return false
+	}
+
+	// Items replaced by an upgrade placed in another slot, equipping them again would swap both items forever
+	if slices.ContainsFunc(ctx.CurrentGame.BlacklistedItems, func(blacklisted data.Item) bool { return blacklisted.UnitID == i.UnitID }) {
+		return false
+	}
+
+	// Stats of unidentified items are unknown, we can not score them
+	if !i.Identified && i.Quality >= item.QualityMagic {
+		return false
+	}
+
+	if class, isClassSpecific := classSpecificItemTypes[i.Type().Code]; isClassSpecific {
+		if target == item.LocationMercenary || class != ctx.Data.PlayerUnit.Class {
+			return false
+		}
+	}
+
+	// There is no way to read merc stats, character stats are used as they are usually close enough
+	lvl, _ := ctx.Data.PlayerUnit.FindStat(stat.Level, 0)
+	str, _ := ctx.Data.PlayerUnit.FindStat(stat.Strength, 0)
+	dex, _ := ctx.Data.PlayerUnit.FindStat(stat.Dexterity, 0)
+
+	desc := i.Desc()
+	levelReq := max(i.LevelReq, desc.RequiredLevel)
+
+	return levelReq <= lvl.Value && desc.RequiredStrength <= str.Value && desc.RequiredDexterity <= dex.Value
+}
+
+// canReplaceItemInSlot handles the weapon and shield slots, where the slot alone is not enough to know if the item
+// can be swapped: a shield should never replace a weapon, and two handed weapons can not be used with a shield.
+func canReplaceItemInSlot(i data.Item, slot item.LocationType, isEquipped bool, current data.Item, equipped map[item.LocationType]data.Item, target item.LocationType) bool {
+	if slot != item.LocLeftArm && slot != item.LocRightArm {
+		return true
+	}
+
+	if i.Type().Code == item.TypeBowQuiver || i.Type().Code == item.TypeCrossbowQuiver {
+		return false
+	}
+
+	// Mercs can only use some weapon types, we only replace weapons and shields by the same type
+	if target == item.LocationMercenary {
+		return isEquipped && current.Type().Code == i.Type().Code
+	}
+
+	if isEquipped {
+		if isShield(current) != isShield(i) {
+			return false
+		}
+		if !isShield(i) && isRangedWeapon(current) != isRangedWeapon(i) {
+			return false
+		}
+	}
+
+	for _, other := range equipped {
+		if other.Location.BodyLocation != item.LocLeftArm && other.Location.BodyLocation != item.LocRightArm {
+			continue
+		}
+		if other.UnitID == current.UnitID {
+			continue
+		}
+		if isShield(i) && isTwoHanded(other) {
+			return false
+		}
+		if isTwoHanded(i) && isShield(other) {
+			return false
+		}
+		// Empty slot, but we already have something of the same kind in the other hand
+		if !isEquipped && isShield(other) == isShield(i) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equippedItems(target item.LocationType) map[item.LocationType]data.Item {
+	ctx := context.Get()
+
+	equipped := make(map[item.LocationType]data.Item)
+	for _, itm := range ctx.Data.Inventory.ByLocation(target) {
+		equipped[itm.Location.BodyLocation] = itm
+	}
+
+	return equipped
+}
+
+func gearScore(i data.Item, weights gearWeights) float64 {
+	ctx := context.Get()
+	class := int(ctx.Data.PlayerUnit.Class)
+
+	score := 0.0
+	for _, s := range i.Stats {
+		weight, found := weights.stats[s.ID]
+		if !found {
+			continue
+		}
+
+		switch s.ID {
+		case stat.AddClassSkills:
+			if s.Layer != class {
+				continue
+			}
+		case stat.AddSkillTab:
+			// Layer contains the class and the skill tree, 3 trees per class
+			if s.Layer/8 != class {
+				continue
+			}
+		}
+
+		score += weight * float64(s.Value)
+	}
+
+	if defense, found := i.FindStat(stat.Defense, 0); found {
+		score += weights.defense * float64(defense.Value)
+	}
+
+	return score + weights.damage*weaponDamage(i)
+}
+
+func weaponDamage(i data.Item) float64 {
+	minDmg, _ := i.FindStat(stat.MinDamage, 0)
+	maxDmg, _ := i.FindStat(stat.MaxDamage, 0)
+	if isTwoHanded(i) {
+		minDmg, _ = i.FindStat(stat.TwoHandedMinDamage, 0)
+		maxDmg, _ = i.FindStat(stat.TwoHandedMaxDamage, 0)
+	}
+
+	return float64(minDmg.Value+maxDmg.Value) / 2
+}
+
+func isShield(i data.Item) bool {
+	return slices.Contains(shieldItemTypes, i.Type().Code)
+}
+
+func isRangedWeapon(i data.Item) bool {
+	return slices.Contains(rangedItemTypes, i.Type().Code)
+}
+
+func isTwoHanded(i data.Item) bool {
+	desc := i.Desc()
+	return isRangedWeapon(i) || (desc.TwoHandMaxDamage > 0 && desc.MaxDamage == 0)
+}
+
+func equipItem(upgrade gearUpgrade, target item.LocationType) error {
+	ctx := context.Get()
+	ctx.SetLastStep("equipItem")
+
+	if err := step.CloseAllMenus(); err != nil {
+		return err
+	}
+
+	// Shift + click equips the item on the character, ctrl + click gives it to the merc when its inventory is open
+	modifier := game.ShiftKey
+	if target == item.LocationMercenary {
+		ctx.HID.PressKeyBinding(ctx.Data.KeyBindings.MercenaryScreen)
+		modifier = game.CtrlKey
+	} else {
+		ctx.HID.PressKeyBinding(ctx.Data.KeyBindings.Inventory)
+	}
+	utils.Sleep(500)
+
+	previous := equippedItems(target)
+	screenPos := ui.GetScreenCoordsForItem(upgrade.item)
+	ctx.HID.ClickWithModifier(game.LeftButton, screenPos.X, screenPos.Y, modifier)
+	utils.Sleep(800)
+	ctx.RefreshGameData()
+
+	for _, itm := range ctx.Data.Inventory.ByLocation(target) {
+		if itm.UnitID == upgrade.item.UnitID {
+			// The game chooses the slot for rings and one handed items, the upgrade was scored against another one
+			if itm.Location.BodyLocation != upgrade.replaces {
+				if replaced, found := previous[itm.Location.BodyLocation]; found {
+					ctx.CurrentGame.BlacklistedItems = append(ctx.CurrentGame.BlacklistedItems, replaced)
+				}
+				step.CloseAllMenus()
+				return fmt.Errorf("item %s equipped in %s instead of %s", itm.Name, itm.Location.BodyLocation, upgrade.replaces)
+			}
+
+			ctx.Logger.Info("Equipped gear upgrade",
+				slog.String("item", string(itm.Name)),
+				slog.String("quality", itm.Quality.ToString()),
+				slog.String("slot", string(upgrade.replaces)),
+				slog.Bool("merc", target == item.LocationMercenary),
+				slog.Float64("gain", upgrade.gain),
+			)
+			event.Send(event.ItemEquipped(event.Text(ctx.Name, fmt.Sprintf("Equipped %s", itm.Name)), itm, target == item.LocationMercenary))
+
+			return step.CloseAllMenus()
+		}
+	}
+
+	return fmt.Errorf("item %s not found in %s after equipping it", upgrade.item.Name, target)
+}
@@ -0,0 +1,150 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/context"
+)
+
+const (
+	testRingID        = 522
+	testSmallShieldID = 329
+	testShortBowID    = 68
+	testHandAxeID     = 0
+)
+
+// newGearTestContext attaches a sorceress context to the current goroutine, subtests run in other goroutines so
+// they can not be used with it
+func newGearTestContext(t *testing.T, equipped ...data.Item) *context.Status {
+	t.Helper()
+
+	ctx := context.NewContext("test")
+	t.Cleanup(ctx.Detach)
+
+	ctx.Data.PlayerUnit.Class = data.Sorceress
+	ctx.Data.PlayerUnit.Stats = stat.Stats{
+		{ID: stat.Level, Value: 30},
+		{ID: stat.Strength, Value: 50},
+		{ID: stat.Dexterity, Value: 50},
+	}
+	ctx.Data.Inventory.AllItems = equipped
+
+	return ctx
+}
+
+func gearItem(unitID data.UnitID, id int, slot item.LocationType, stats ...stat.Data) data.Item {
+	location := item.Location{LocationType: item.LocationInventory}
+	if slot != "" {
+		location = item.Location{LocationType: item.LocationEquipped, BodyLocation: slot}
+	}
+
+	return data.Item{ID: id, UnitID: unitID, Quality: item.QualityMagic, Identified: true, Location: location, Stats: stats}
+}
+
+func TestGearScore(t *testing.T) {
+	newGearTestContext(t)
+
+	ring := gearItem(1, testRingID, "",
+		stat.Data{ID: stat.AllSkills, Value: 1},
+		stat.Data{ID: stat.FireResist, Value: 10},
+		// Skills for another class are not worth anything
+		stat.Data{ID: stat.AddClassSkills, Value: 2, Layer: int(data.Necromancer)},
+	)
+	if score := gearScore(ring, sorceressGearWeights); score != 115 {
+		t.Errorf("expected score 115, got %f", score)
+	}
+
+	ring.Stats = append(ring.Stats, stat.Data{ID: stat.AddClassSkills, Value: 1, Layer: int(data.Sorceress)})
+	if score := gearScore(ring, sorceressGearWeights); score != 215 {
+		t.Errorf("expected class skills to be added, got %f", score)
+	}
+}
+
+func TestGearUpgradeForRings(t *testing.T) {
+	left := gearItem(2, testRingID, item.LocLeftRing, stat.Data{ID: stat.FireResist, Value: 20})
+	right := gearItem(3, testRingID, item.LocRightRing, stat.Data{ID: stat.FireResist, Value: 10})
+	newGearTestContext(t, left, right)
+
+	// Worst ring is the one replaced
+	better := gearItem(4, testRingID, "", stat.Data{ID: stat.FireResist, Value: 20}, stat.Data{ID: stat.ColdResist, Value: 10})
+	upgrade, found := gearUpgradeFor(better, item.LocationEquipped)
+	if !found || upgrade.replaces != item.LocRightRing || upgrade.gain != 30 {
+		t.Errorf("expected right ring to be replaced with gain 30, got %+v (found: %v)", upgrade, found)
+	}
+
+	// Better than the worst ring, but not enough to swap them
+	tiny := gearItem(5, testRingID, "", stat.Data{ID: stat.FireResist, Value: 10}, stat.Data{ID: stat.MagicFind, Value: 1})
+	if upgrade, found = gearUpgradeFor(tiny, item.LocationEquipped); found {
+		t.Errorf("expected no upgrade below the minimum ratio, got %+v", upgrade)
+	}
+
+	// Unidentified items can not be scored
+	better.Identified = false
+	if _, found = gearUpgradeFor(better, item.LocationEquipped); found {
+		t.Error("unidentified items should not be upgrades")
+	}
+
+	upgrade, found = findGearUpgrade([]data.Item{tiny, better, gearItem(6, testRingID, "")}, item.LocationEquipped)
+	if found {
+		t.Errorf("expected no upgrade, got %+v", upgrade)
+	}
+}
+
+func TestGearUpgradeBlacklisted(t *testing.T) {
+	right := gearItem(7, testRingID, item.LocRightRing)
+	ctx := newGearTestContext(t, right)
+
+	ring := gearItem(8, testRingID, "", stat.Data{ID: stat.FireResist, Value: 20})
+	if _, found := gearUpgradeFor(ring, item.LocationEquipped); !found {
+		t.Fatal("expected ring to be an upgrade")
+	}
+
+	ctx.CurrentGame.BlacklistedItems = append(ctx.CurrentGame.BlacklistedItems, ring)
+	if _, found := gearUpgradeFor(ring, item.LocationEquipped); found {
+		t.Error("blacklisted items should not be equipped again")
+	}
+}
+
+func TestCanReplaceItemInSlot(t *testing.T) {
+	bow := gearItem(9, testShortBowID, item.LocLeftArm)
+	shield := gearItem(10, testSmallShieldID, "")
+	axe := gearItem(11, testHandAxeID, item.LocLeftArm)
+
+	tests := []struct {
+		name     string
+		item     data.Item
+		slot     item.LocationType
+		equipped []data.Item
+		expected bool
+	}{
+		{name: "shield with a one handed weapon", item: shield, slot: item.LocRightArm, equipped: []data.Item{axe}, expected: true},
+		{name: "shield with a bow", item: shield, slot: item.LocRightArm, equipped: []data.Item{bow}, expected: false},
+		{name: "shield replacing a weapon", item: shield, slot: item.LocLeftArm, equipped: []data.Item{axe}, expected: false},
+		{name: "bow replacing a melee weapon", item: gearItem(12, testShortBowID, ""), slot: item.LocLeftArm, equipped: []data.Item{axe}, expected: false},
+		{name: "weapon replacing a weapon", item: gearItem(13, testHandAxeID, ""), slot: item.LocLeftArm, equipped: []data.Item{axe}, expected: true},
+		{name: "second weapon in the empty hand", item: gearItem(14, testHandAxeID, ""), slot: item.LocRightArm, equipped: []data.Item{axe}, expected: false},
+	}
+
+	for _, tt := range tests {
+		equipped := make(map[item.LocationType]data.Item)
+		for _, itm := range tt.equipped {
+			equipped[itm.Location.BodyLocation] = itm
+		}
+		current, isEquipped := equipped[tt.slot]
+
+		if got := canReplaceItemInSlot(tt.item, tt.slot, isEquipped, current, equipped, item.LocationEquipped); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestIsGearUpgradeOnlyForLevelingCharacters(t *testing.T) {
+	newGearTestContext(t)
+
+	if IsGearUpgrade(gearItem(15, testRingID, "", stat.Data{ID: stat.FireResist, Value: 20})) {
+		t.Error("farming characters should keep their gear")
+	}
+}
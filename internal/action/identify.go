@@ -2,6 +2,8 @@ package action
 
 import (
 	"fmt"
+	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
@@ -21,26 +23,28 @@ func IdentifyAll(skipIdentify bool) error {
 	ctx := context.Get()
 	ctx.SetLastAction("IdentifyAll")
 
-	items := itemsToIdentify()
+	cainItems, tomeItems := itemsToIdentify()
 
 	ctx.Logger.Debug("Checking for items to identify...")
-	if len(items) == 0 || skipIdentify {
+	if len(cainItems)+len(tomeItems) == 0 || skipIdentify {
 		ctx.Logger.Debug("No items to identify...")
 		return nil
 	}
 
-	if ctx.CharacterCfg.Game.UseCainIdentify {
+	if len(cainItems) > 0 {
 		ctx.Logger.Debug("Identifying all item with Cain...")
 		// Close any open menus first
 		step.CloseAllMenus()
 		utils.Sleep(500)
 
 		err := CainIdentify()
-		// if identifying with cain fails then we should continue to identify using tome
+		// Cain identifies the whole inventory, so the tome items are identified too
 		if err == nil {
 			return nil
 		}
+		// if identifying with cain fails then we should continue to identify using tome
 		ctx.Logger.Debug("Identifying with Cain failed, continuing with identifying with tome", "err", err)
+		tomeItems = append(tomeItems, cainItems...)
 	}
 
 	idTome, found := ctx.Data.Inventory.Find(item.TomeOfIdentify, item.LocationInventory)
@@ -49,12 +53,12 @@ func IdentifyAll(skipIdentify bool) error {
 		return nil
 	}
 
-	if st, statFound := idTome.FindStat(stat.Quantity, 0); !statFound || st.Value < len(items) {
+	if st, statFound := idTome.FindStat(stat.Quantity, 0); !statFound || st.Value < len(tomeItems) {
 		ctx.Logger.Info("Not enough ID scrolls, refilling...")
 		VendorRefill(true, false)
 	}
 
-	ctx.Logger.Info(fmt.Sprintf("Identifying %d items...", len(items)))
+	ctx.Logger.Info(fmt.Sprintf("Identifying %d items...", len(tomeItems)))
 
 	// Close all menus to prevent issues
 	step.CloseAllMenus()
@@ -63,7 +67,7 @@ func IdentifyAll(skipIdentify bool) error {
 		utils.Sleep(1000) // Add small delay to allow the game to open the inventory
 	}
 
-	for _, i := range items {
+	for _, i := range tomeItems {
 		identifyItem(idTome, i)
 	}
 	step.CloseAllMenus()
@@ -113,36 +117,101 @@ func CainIdentify() error {
 	return nil
 }
 
-func itemsToIdentify() (items []data.Item) {
+type identifyDecision string
+
+const (
+	identifyKeepUnidentified identifyDecision = "keep unidentified"
+	identifyWithTome         identifyDecision = "identify with tome"
+	identifyWithCain         identifyDecision = "identify with Cain"
+	identifySellUnidentified identifyDecision = "sell unidentified"
+)
+
+// identifyDecisionFor decides what to do with an unidentified item. Rules are evaluated before identifying, a full
+// match means the rule doesn't depend on the hidden stats (unid Annihilus, Griffon's...) and a partial match means
+// we need to identify it to know. Items are identified with Cain only if cainAllowed, he identifies the whole
+// inventory so it can not be used while there are items to keep unidentified. Returns the rule that triggered the
+// decision, empty if none.
+func identifyDecisionFor(i data.Item, cainAllowed bool) (identifyDecision, nip.Rule, string) {
+	ctx := context.Get()
+
+	identifyMethod := identifyWithTome
+	if cainAllowed {
+		identifyMethod = identifyWithCain
+	}
+
+	rule, result := ctx.CharacterCfg.Runtime.Rules.EvaluateAll(i)
+	switch result {
+	case nip.RuleResultFullMatch:
+		return identifyKeepUnidentified, rule, "rule matches the unidentified item"
+	case nip.RuleResultPartial:
+		return identifyMethod, rule, "rule needs the item stats"
+	}
+
+	if shouldKeepRecipeItem(i) {
+		return identifyKeepUnidentified, nip.Rule{}, recipeItemStashReason
+	}
+
+	// Leveling characters need to know the stats to check if it's an upgrade
+	if _, isLevelingChar := ctx.Char.(context.LevelingCharacter); isLevelingChar {
+		return identifyMethod, nip.Rule{}, "leveling character gear evaluation"
+	}
+
+	return identifySellUnidentified, nip.Rule{}, "no rule can match the item"
+}
+
+// itemsToIdentify returns the inventory items to identify, grouped by the method decided for each of them
+func itemsToIdentify() (cainItems, tomeItems []data.Item) {
 	ctx := context.Get()
 	ctx.SetLastAction("itemsToIdentify")
 
+	unidentified := make([]data.Item, 0)
 	for _, i := range ctx.Data.Inventory.ByLocation(item.LocationInventory) {
 		if i.Identified || i.Quality == item.QualityNormal || i.Quality == item.QualitySuperior {
 			continue
 		}
+		unidentified = append(unidentified, i)
+	}
 
-		// Skip identifying items that fully match a rule when unid
-		if _, result := ctx.CharacterCfg.Runtime.Rules.EvaluateAll(i); result == nip.RuleResultFullMatch {
-			continue
+	cainAllowed := ctx.CharacterCfg.Game.UseCainIdentify && !slices.ContainsFunc(unidentified, func(i data.Item) bool {
+		decision, _, _ := identifyDecisionFor(i, false)
+		return decision == identifyKeepUnidentified
+	})
+
+	for _, i := range unidentified {
+		decision, rule, reason := identifyDecisionFor(i, cainAllowed)
+		ctx.Logger.Debug("Identify decision",
+			slog.String("item", string(i.Name)),
+			slog.String("quality", i.Quality.ToString()),
+			slog.String("decision", string(decision)),
+			slog.String("reason", reason),
+			slog.String("nipFile", fmt.Sprintf("%s:%d", rule.Filename, rule.LineNumber)),
+			slog.String("rawRule", rule.RawLine),
+		)
+
+		switch decision {
+		case identifyWithCain:
+			cainItems = append(cainItems, i)
+		case identifyWithTome:
+			tomeItems = append(tomeItems, i)
 		}
-
-		items = append(items, i)
 	}
 
 	return
 }
 
+// HaveItemsToStashUnidentified returns true if there are items that should be stashed before identifying them
 func HaveItemsToStashUnidentified() bool {
 	ctx := context.Get()
 	ctx.SetLastAction("HaveItemsToStashUnidentified")
 
 	items := ctx.Data.Inventory.ByLocation(item.LocationInventory)
 	for _, i := range items {
-		if !i.Identified {
-			if _, result := ctx.CharacterCfg.Runtime.Rules.EvaluateAll(i); result == nip.RuleResultFullMatch {
-				return true
-			}
+		if i.Identified || i.Quality == item.QualityNormal || i.Quality == item.QualitySuperior {
+			continue
+		}
+
+		if decision, _, _ := identifyDecisionFor(i, false); decision == identifyKeepUnidentified {
+			return true
 		}
 	}
 
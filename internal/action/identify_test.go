@@ -0,0 +1,96 @@
+package action
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/context"
+)
+
+const (
+	testAmuletID = 520
+	testJewelID  = 643
+)
+
+func newIdentifyTestContext(t *testing.T) *context.Status {
+	t.Helper()
+
+	ctx := context.NewContext("test")
+	t.Cleanup(ctx.Detach)
+
+	ctx.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx.CharacterCfg = &config.CharacterCfg{}
+	ctx.CharacterCfg.CubeRecipes.Enabled = true
+	ctx.CharacterCfg.CubeRecipes.EnabledRecipes = []string{"Hitpower Helm"}
+
+	rules := []string{
+		"[name] == ring && [quality] == unique",
+		"[name] == amulet && [quality] == rare # [strength] >= 10",
+	}
+	for i, raw := range rules {
+		rule, err := nip.NewRule(raw, "identify.nip", i+1)
+		if err != nil {
+			t.Fatalf("error parsing rule %q: %v", raw, err)
+		}
+		ctx.CharacterCfg.Runtime.Rules = append(ctx.CharacterCfg.Runtime.Rules, rule)
+	}
+
+	return ctx
+}
+
+func unidentifiedItem(unitID data.UnitID, id int, name item.Name, quality item.Quality) data.Item {
+	return data.Item{ID: id, UnitID: unitID, Name: name, Quality: quality, Location: item.Location{LocationType: item.LocationInventory}}
+}
+
+func TestIdentifyDecisionFor(t *testing.T) {
+	newIdentifyTestContext(t)
+
+	tests := []struct {
+		name        string
+		item        data.Item
+		cainAllowed bool
+		expected    identifyDecision
+		ruleLine    int
+	}{
+		{name: "full match", item: unidentifiedItem(1, testRingID, "Ring", item.QualityUnique), expected: identifyKeepUnidentified, ruleLine: 1},
+		{name: "partial match", item: unidentifiedItem(2, testAmuletID, "Amulet", item.QualityRare), expected: identifyWithTome, ruleLine: 2},
+		{name: "partial match with Cain", item: unidentifiedItem(2, testAmuletID, "Amulet", item.QualityRare), cainAllowed: true, expected: identifyWithCain, ruleLine: 2},
+		{name: "recipe item", item: unidentifiedItem(3, testJewelID, "Jewel", item.QualityMagic), expected: identifyKeepUnidentified},
+		{name: "no match", item: unidentifiedItem(4, testRingID, "Ring", item.QualityMagic), expected: identifySellUnidentified},
+	}
+
+	for _, tt := range tests {
+		decision, rule, _ := identifyDecisionFor(tt.item, tt.cainAllowed)
+		if decision != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, decision)
+		}
+		if rule.LineNumber != tt.ruleLine {
+			t.Errorf("%s: expected rule at line %d, got %d", tt.name, tt.ruleLine, rule.LineNumber)
+		}
+	}
+}
+
+func TestItemsToIdentify(t *testing.T) {
+	ctx := newIdentifyTestContext(t)
+	ctx.CharacterCfg.Game.UseCainIdentify = true
+
+	amulet := unidentifiedItem(1, testAmuletID, "Amulet", item.QualityRare)
+	ctx.Data.Inventory.AllItems = []data.Item{amulet, unidentifiedItem(2, testRingID, "Ring", item.QualityMagic)}
+
+	cainItems, tomeItems := itemsToIdentify()
+	if len(cainItems) != 1 || cainItems[0].UnitID != amulet.UnitID || len(tomeItems) != 0 {
+		t.Errorf("expected the amulet to be identified with Cain, got %v and %v", cainItems, tomeItems)
+	}
+
+	// Cain would identify the unique ring too, so the tome is used until it's stashed
+	ctx.Data.Inventory.AllItems = append(ctx.Data.Inventory.AllItems, unidentifiedItem(3, testRingID, "Ring", item.QualityUnique))
+	cainItems, tomeItems = itemsToIdentify()
+	if len(cainItems) != 0 || len(tomeItems) != 1 || tomeItems[0].UnitID != amulet.UnitID {
+		t.Errorf("expected the amulet to be identified with the tome, got %v and %v", cainItems, tomeItems)
+	}
+}
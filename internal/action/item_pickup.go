@@ -307,6 +307,16 @@ func shouldBePickedUp(i data.Item) bool {
 		return true
 	}
 
+	// Magic bases for enabled crafting recipes
+	if isCraftingBase(i) {
+		return true
+	}
+
+	// Pickup items that would be better than the equipped ones
+	if isLevelingChar && IsGearUpgrade(i) {
+		return true
+	}
+
 	// Pickup all magic or superior items if total gold is low, filter will not pass and items will be sold to vendor
 	minGoldPickupThreshold := ctx.CharacterCfg.Game.MinGoldPickupThreshold
 	if ctx.Data.PlayerUnit.TotalPlayerGold() < minGoldPickupThreshold && i.Quality >= item.QualityMagic {
@@ -1,10 +1,14 @@
 package action
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
 	"slices"
 
 	"github.com/hectorgimenez/koolo/internal/action/step"
 	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/town"
 	"github.com/hectorgimenez/koolo/internal/ui"
@@ -52,107 +56,179 @@ var uiSkillRowPositionLegacy = [6]int{110, 195, 275, 355, 440, 520}
 var uiSkillColumnPositionLegacy = [3]int{690, 770, 855}
 
 func EnsureStatPoints() error {
-	// TODO finish this
-	return nil
-	//return NewStepChain(func(d game.Data) []step.Step {
-	//	char, isLevelingChar := b.ch.(LevelingCharacter)
-	//	_, unusedStatPoints := d.PlayerUnit.FindStat(stat.StatPoints, 0)
-	//	if !isLevelingChar || !unusedStatPoints {
-	//		if d.OpenMenus.Character {
-	//			return []step.Step{
-	//				step.SyncStep(func(_ game.Data) error {
-	//					b.HID.PressKey(win.VK_ESCAPE)
-	//					return nil
-	//				}),
-	//			}
-	//		}
-	//
-	//		return nil
-	//	}
-	//
-	//	for st, targetPoints := range char.StatPoints(d) {
-	//		currentPoints, found := d.PlayerUnit.FindStat(st, 0)
-	//		if !found || currentPoints.Value >= targetPoints {
-	//			continue
-	//		}
-	//
-	//		if !d.OpenMenus.Character {
-	//			return []step.Step{
-	//				step.SyncStep(func(_ game.Data) error {
-	//					b.HID.PressKeyBinding(d.KeyBindings.CharacterScreen)
-	//					return nil
-	//				}),
-	//			}
-	//		}
-	//
-	//		var statBtnPosition data.Position
-	//		if d.LegacyGraphics {
-	//			statBtnPosition = uiStatButtonPositionLegacy[st]
-	//		} else {
-	//			statBtnPosition = uiStatButtonPosition[st]
-	//		}
-	//
-	//		return []step.Step{
-	//			step.SyncStep(func(_ game.Data) error {
-	//				utils.Sleep(100)
-	//				b.HID.Click(game.LeftButton, statBtnPosition.X, statBtnPosition.Y)
-	//				utils.Sleep(500)
-	//				return nil
-	//			}),
-	//		}
-	//	}
-	//
-	//	return nil
-	//}, RepeatUntilNoSteps())
+	ctx := context.Get()
+	ctx.SetLastAction("EnsureStatPoints")
+
+	char, isLevelingChar := ctx.Char.(context.LevelingCharacter)
+	if !isLevelingChar {
+		return nil
+	}
+
+	if unusedPoints, found := ctx.Data.PlayerUnit.FindStat(stat.StatPoints, 0); !found || unusedPoints.Value == 0 {
+		return nil
+	}
+
+	allocated := make(map[stat.ID]int)
+	for _, target := range char.StatPoints() {
+		for {
+			unusedPoints, found := ctx.Data.PlayerUnit.FindStat(stat.StatPoints, 0)
+			if !found || unusedPoints.Value == 0 {
+				break
+			}
+
+			// Targets are base values, items giving stats don't count
+			currentPoints, _ := ctx.Data.PlayerUnit.BaseStats.FindStat(target.Stat, 0)
+			if currentPoints.Value >= target.Points {
+				break
+			}
+
+			if err := allocateStatPoint(target.Stat); err != nil {
+				step.CloseAllMenus()
+				return err
+			}
+			allocated[target.Stat]++
+		}
+	}
+
+	if len(allocated) > 0 {
+		lvl, _ := ctx.Data.PlayerUnit.FindStat(stat.Level, 0)
+		ctx.Logger.Info("Stat points allocated", slog.Int("level", lvl.Value), slog.Any("points", allocated))
+		event.Send(event.StatPointsAllocated(event.Text(ctx.Name, fmt.Sprintf("Stat points allocated at level %d", lvl.Value)), lvl.Value, allocated))
+	}
+
+	return step.CloseAllMenus()
+}
+
+// allocateStatPoint clicks the stat button and verifies the point has been spent reading the memory
+func allocateStatPoint(st stat.ID) error {
+	ctx := context.Get()
+
+	statBtnPosition, found := uiStatButtonPosition[st]
+	if ctx.Data.LegacyGraphics {
+		statBtnPosition, found = uiStatButtonPositionLegacy[st]
+	}
+	if !found {
+		return fmt.Errorf("stat %d can not be allocated", st)
+	}
+
+	if !ctx.Data.OpenMenus.Character {
+		ctx.HID.PressKeyBinding(ctx.Data.KeyBindings.CharacterScreen)
+		utils.Sleep(300)
+		ctx.RefreshGameData()
+		if !ctx.Data.OpenMenus.Character {
+			return errors.New("failed opening the character screen")
+		}
+	}
+
+	previousPoints, _ := ctx.Data.PlayerUnit.BaseStats.FindStat(st, 0)
+	previousUnused, _ := ctx.Data.PlayerUnit.FindStat(stat.StatPoints, 0)
+	for attempt := 0; attempt < 3; attempt++ {
+		ctx.HID.Click(game.LeftButton, statBtnPosition.X, statBtnPosition.Y)
+
+		// Memory can take a while to reflect the click, clicking again before that would spend more than one point
+		for poll := 0; poll < 10; poll++ {
+			utils.Sleep(150)
+			ctx.RefreshGameData()
+
+			currentPoints, _ := ctx.Data.PlayerUnit.BaseStats.FindStat(st, 0)
+			currentUnused, _ := ctx.Data.PlayerUnit.FindStat(stat.StatPoints, 0)
+			if currentPoints.Value > previousPoints.Value || currentUnused.Value < previousUnused.Value {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("failed allocating stat point to stat %d", st)
 }
 
+// EnsureSkillPoints spends the unused skill points following the order returned by the leveling character, every
+// entry is one point, so skills already having enough points are skipped
 func EnsureSkillPoints() error {
-	// TODO finish this
-	return nil
-	//ctx := context.Get()
-	//
-	//char, isLevelingChar := ctx.Char.(LevelingCharacter)
-	//availablePoints, unusedSkillPoints := ctx.Data.PlayerUnit.FindStat(stat.SkillPoints, 0)
-	//
-	//assignedPoints := make(map[skill.ID]int)
-	//for _, sk := range char.SkillPoints() {
-	//	currentPoints, found := assignedPoints[sk]
-	//	if !found {
-	//		currentPoints = 0
-	//	}
-	//
-	//	assignedPoints[sk] = currentPoints + 1
-	//
-	//	characterPoints, found := ctx.Data.PlayerUnit.Skills[sk]
-	//	if !found || int(characterPoints.Level) < assignedPoints[sk] {
-	//		skillDesc, skFound := skill.Desc[sk]
-	//		if !skFound {
-	//			ctx.Logger.Error("skill not found for character", slog.Any("skill", sk))
-	//			return nil
-	//		}
-	//
-	//		if !ctx.Data.OpenMenus.SkillTree {
-	//			ctx.HID.PressKeyBinding(ctx.Data.KeyBindings.SkillTree)
-	//		}
-	//
-	//		utils.Sleep(100)
-	//		if ctx.Data.LegacyGraphics {
-	//			ctx.HID.Click(game.LeftButton, uiSkillPagePositionLegacy[skillDesc.Page-1].X, uiSkillPagePositionLegacy[skillDesc.Page-1].Y)
-	//		} else {
-	//			ctx.HID.Click(game.LeftButton, uiSkillPagePosition[skillDesc.Page-1].X, uiSkillPagePosition[skillDesc.Page-1].Y)
-	//		}
-	//		utils.Sleep(200)
-	//		if ctx.Data.LegacyGraphics {
-	//			ctx.HID.Click(game.LeftButton, uiSkillColumnPositionLegacy[skillDesc.Column-1], uiSkillRowPositionLegacy[skillDesc.Row-1])
-	//		} else {
-	//			ctx.HID.Click(game.LeftButton, uiSkillColumnPosition[skillDesc.Column-1], uiSkillRowPosition[skillDesc.Row-1])
-	//		}
-	//		utils.Sleep(500)
-	//		return nil
-	//	}
-	//}
-	//
-	//return nil
+	ctx := context.Get()
+	ctx.SetLastAction("EnsureSkillPoints")
+
+	char, isLevelingChar := ctx.Char.(context.LevelingCharacter)
+	if !isLevelingChar {
+		return nil
+	}
+
+	if unusedPoints, found := ctx.Data.PlayerUnit.FindStat(stat.SkillPoints, 0); !found || unusedPoints.Value == 0 {
+		return nil
+	}
+
+	required := make(map[skill.ID]int)
+	allocated := make(map[skill.ID]int)
+	for _, sk := range char.SkillPoints() {
+		unusedPoints, found := ctx.Data.PlayerUnit.FindStat(stat.SkillPoints, 0)
+		if !found || unusedPoints.Value == 0 {
+			break
+		}
+
+		required[sk]++
+		if int(ctx.Data.PlayerUnit.Skills[sk].Level) >= required[sk] {
+			continue
+		}
+
+		if err := allocateSkillPoint(sk); err != nil {
+			step.CloseAllMenus()
+			return err
+		}
+		allocated[sk]++
+	}
+
+	if len(allocated) > 0 {
+		lvl, _ := ctx.Data.PlayerUnit.FindStat(stat.Level, 0)
+		ctx.Logger.Info("Skill points allocated", slog.Int("level", lvl.Value), slog.Any("points", allocated))
+	}
+
+	return step.CloseAllMenus()
+}
+
+// allocateSkillPoint clicks the skill in the skill tree and verifies the point has been spent reading the memory
+func allocateSkillPoint(sk skill.ID) error {
+	ctx := context.Get()
+
+	desc, found := skill.Desc[sk]
+	if !found || desc.Page < 1 || desc.Page > 3 || desc.Row < 1 || desc.Row > 6 || desc.Column < 1 || desc.Column > 3 {
+		return fmt.Errorf("skill %d can not be allocated", sk)
+	}
+
+	if !ctx.Data.OpenMenus.SkillTree {
+		ctx.HID.PressKeyBinding(ctx.Data.KeyBindings.SkillTree)
+		utils.Sleep(300)
+		ctx.RefreshGameData()
+		if !ctx.Data.OpenMenus.SkillTree {
+			return errors.New("failed opening the skill tree")
+		}
+	}
+
+	pagePosition := uiSkillPagePosition[desc.Page-1]
+	skillPosition := data.Position{X: uiSkillColumnPosition[desc.Column-1], Y: uiSkillRowPosition[desc.Row-1]}
+	if ctx.Data.LegacyGraphics {
+		pagePosition = uiSkillPagePositionLegacy[desc.Page-1]
+		skillPosition = data.Position{X: uiSkillColumnPositionLegacy[desc.Column-1], Y: uiSkillRowPositionLegacy[desc.Row-1]}
+	}
+	ctx.HID.Click(game.LeftButton, pagePosition.X, pagePosition.Y)
+	utils.Sleep(200)
+
+	previousLevel := ctx.Data.PlayerUnit.Skills[sk].Level
+	previousUnused, _ := ctx.Data.PlayerUnit.FindStat(stat.SkillPoints, 0)
+	for attempt := 0; attempt < 3; attempt++ {
+		ctx.HID.Click(game.LeftButton, skillPosition.X, skillPosition.Y)
+
+		// Same as stat points, wait for the memory to reflect the click before clicking again
+		for poll := 0; poll < 10; poll++ {
+			utils.Sleep(150)
+			ctx.RefreshGameData()
+
+			currentUnused, _ := ctx.Data.PlayerUnit.FindStat(stat.SkillPoints, 0)
+			if ctx.Data.PlayerUnit.Skills[sk].Level > previousLevel || currentUnused.Value < previousUnused.Value {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("failed allocating skill point to %s", desc.Name)
 }
 
 func UpdateQuestLog() error {
@@ -362,22 +438,32 @@ func ResetStats() error {
 
 	ch, isLevelingChar := ctx.Char.(context.LevelingCharacter)
 	if isLevelingChar && ch.ShouldResetSkills() {
-		currentArea := ctx.Data.PlayerUnit.Area
-		if ctx.Data.PlayerUnit.Area != area.RogueEncampment {
-			err := WayPoint(area.RogueEncampment)
-			if err != nil {
-				return err
-			}
-		}
-		InteractNPC(npc.Akara)
-		ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_DOWN, win.VK_RETURN)
-		utils.Sleep(1000)
-		ctx.HID.KeySequence(win.VK_HOME, win.VK_RETURN)
+		return RespecAtAkara()
+	}
+
+	return nil
+}
+
+// RespecAtAkara resets skills and stats talking to Akara, it's free only once per difficulty
+func RespecAtAkara() error {
+	ctx := context.Get()
+	ctx.SetLastAction("RespecAtAkara")
 
-		if currentArea != area.RogueEncampment {
-			return WayPoint(currentArea)
+	currentArea := ctx.Data.PlayerUnit.Area
+	if ctx.Data.PlayerUnit.Area != area.RogueEncampment {
+		err := WayPoint(area.RogueEncampment)
+		if err != nil {
+			return err
 		}
 	}
+	InteractNPC(npc.Akara)
+	ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_DOWN, win.VK_RETURN)
+	utils.Sleep(1000)
+	ctx.HID.KeySequence(win.VK_HOME, win.VK_RETURN)
+
+	if currentArea != area.RogueEncampment {
+		return WayPoint(currentArea)
+	}
 
 	return nil
 }
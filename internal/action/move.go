@@ -257,8 +257,7 @@ func MoveTo(toFunc func() (data.Position, bool)) error {
 		}
 
 		// Check for monsters close to player
-		closestMonster := data.Monster{}
-		closestMonsterDistance := 9999999
+		candidates := make([]data.Monster, 0)
 		targetedNormalEnemies := make([]data.Monster, 0)
 		targetedElites := make([]data.Monster, 0)
 		minDistance := 6
@@ -283,20 +282,20 @@ func MoveTo(toFunc func() (data.Position, bool)) error {
 				appended = true
 			}
 
-			if appended && dist < closestMonsterDistance {
-				closestMonsterDistance = dist
-				closestMonster = m
+			if appended {
+				candidates = append(candidates, m)
 			}
 		}
 
 		if len(targetedNormalEnemies) > 5 || len(targetedElites) > 0 || (stuck && (len(targetedNormalEnemies) > 0 || len(targetedElites) > 0)) || (pather.IsNarrowMap(ctx.Data.PlayerUnit.Area) && (len(targetedNormalEnemies) > 0 || len(targetedElites) > 0)) {
+			target, _ := ctx.Data.BestTarget(candidates, ctx.IsImmuneToCharacter)
 			if stuck {
 				ctx.Logger.Info("Character stuck and monsters detected, trying to kill monsters around")
 			} else {
-				ctx.Logger.Info(fmt.Sprintf("At least %d monsters detected close to the character, targeting closest one: %d", len(targetedNormalEnemies)+len(targetedElites), closestMonster.Name))
+				ctx.Logger.Info(fmt.Sprintf("At least %d monsters detected close to the character, targeting: %d", len(targetedNormalEnemies)+len(targetedElites), target.Name))
 			}
 
-			path, _, mPathFound := ctx.PathFinder.GetPath(closestMonster.Position)
+			path, _, mPathFound := ctx.PathFinder.GetPath(target.Position)
 			if mPathFound {
 				doorIsBlocking := false
 				for _, o := range ctx.Data.Objects {
@@ -308,7 +307,7 @@ func MoveTo(toFunc func() (data.Position, bool)) error {
 
 				if !doorIsBlocking {
 					ctx.Char.KillMonsterSequence(func(d game.Data) (data.UnitID, bool) {
-						return closestMonster.UnitID, true
+						return target.UnitID, true
 					}, nil)
 				}
 			}
@@ -0,0 +1,123 @@
+package action
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/lxn/win"
+)
+
+// Vendors have up to 4 tabs (armor, weapons, weapons 2, misc)
+var vendorTabs = []int{1, 2, 3, 4}
+
+// ShopAtVendor opens the trade window of the given vendor and buys the items matching the shopping rules
+func ShopAtVendor(vendor npc.ID) (int, error) {
+	ctx := context.Get()
+	ctx.SetLastAction("ShopAtVendor")
+
+	if err := InteractNPC(vendor); err != nil {
+		return 0, err
+	}
+
+	// Jamella trade button is the first one
+	if vendor == npc.Jamella {
+		ctx.HID.KeySequence(win.VK_HOME, win.VK_RETURN)
+	} else {
+		ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+	}
+
+	bought := ShopVendorItems(vendor)
+
+	return bought, step.CloseAllMenus()
+}
+
+// ShopVendorItems checks all the vendor tabs buying the items matching the shopping rules while gold allows it,
+// vendor window should be already open. Returns the number of items bought.
+func ShopVendorItems(vendor npc.ID) int {
+	ctx := context.Get()
+	ctx.SetLastAction("ShopVendorItems")
+
+	if len(ctx.CharacterCfg.Runtime.ShoppingRules) == 0 {
+		return 0
+	}
+
+	bought := 0
+	for _, itm := range ctx.Data.Inventory.ByLocation(item.LocationVendor) {
+		rule, res := ctx.CharacterCfg.Runtime.ShoppingRules.EvaluateAll(itm)
+		if res != nip.RuleResultFullMatch {
+			continue
+		}
+
+		if shoppingGoldReserveReached() {
+			ctx.Logger.Info("Not enough gold to keep shopping", slog.Int("gold", ctx.Data.PlayerUnit.TotalPlayerGold()))
+			return bought
+		}
+
+		if !buyShoppingItem(itm) {
+			ctx.Logger.Warn("Failed buying item, not found in the vendor tabs, not enough gold or inventory space", slog.String("item", string(itm.Name)))
+			continue
+		}
+
+		bought++
+		ctx.Logger.Info("Item bought from vendor",
+			slog.String("item", string(itm.Name)),
+			slog.String("quality", itm.Quality.ToString()),
+			slog.String("rule", rule.RawLine),
+		)
+		event.Send(event.ItemPurchased(
+			event.Text(ctx.Name, fmt.Sprintf("Item %s [%s] bought from vendor", itm.Name, itm.Quality.ToString())),
+			data.Drop{Item: itm, Rule: rule.RawLine, RuleFile: rule.Filename + ":" + strconv.Itoa(rule.LineNumber)},
+			vendor,
+		))
+	}
+
+	return bought
+}
+
+// shoppingGoldReserveReached returns true when the gold is below the configured reserve, gold is kept for potions,
+// repairs and merc revives
+func shoppingGoldReserveReached() bool {
+	ctx := context.Get()
+
+	return ctx.Data.PlayerUnit.TotalPlayerGold() < ctx.CharacterCfg.Game.Shopping.MinGoldReserve
+}
+
+// buyShoppingItem buys the item and checks it was moved to the inventory, vendor prices can not be read so this is
+// the only way to know if we could afford it
+func buyShoppingItem(itm data.Item) bool {
+	ctx := context.Get()
+
+	if !buyVendorItem(itm, 1, vendorTabs...) {
+		return false
+	}
+	ctx.RefreshGameData()
+
+	for _, invItem := range ctx.Data.Inventory.ByLocation(item.LocationInventory) {
+		if invItem.UnitID == itm.UnitID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsShoppingItem returns true if the item matches the shopping rules, these items should be stashed instead of sold
+func IsShoppingItem(i data.Item) (nip.Rule, bool) {
+	ctx := context.Get()
+
+	if len(ctx.CharacterCfg.Runtime.ShoppingRules) == 0 {
+		return nip.Rule{}, false
+	}
+
+	rule, res := ctx.CharacterCfg.Runtime.ShoppingRules.EvaluateAll(i)
+
+	return rule, res == nip.RuleResultFullMatch
+}
@@ -0,0 +1,97 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/context"
+)
+
+func newShoppingTestContext(t *testing.T, rules ...string) *context.Status {
+	t.Helper()
+
+	ctx := context.NewContext("test")
+	t.Cleanup(ctx.Detach)
+
+	ctx.CharacterCfg = &config.CharacterCfg{}
+	for i, raw := range rules {
+		rule, err := nip.NewRule(raw, "shopping.nip", i+1)
+		if err != nil {
+			t.Fatalf("error parsing rule %q: %v", raw, err)
+		}
+		ctx.CharacterCfg.Runtime.ShoppingRules = append(ctx.CharacterCfg.Runtime.ShoppingRules, rule)
+	}
+
+	return ctx
+}
+
+func TestIsShoppingItem(t *testing.T) {
+	newShoppingTestContext(t, "[name] == ring && [quality] == unique # [fireresist] >= 20")
+
+	ring := func(quality item.Quality, identified bool, fireResist int) data.Item {
+		return data.Item{ID: testRingID, Name: "Ring", Quality: quality, Identified: identified, Stats: stat.Stats{{ID: stat.FireResist, Value: fireResist}}}
+	}
+
+	tests := []struct {
+		name     string
+		item     data.Item
+		expected bool
+	}{
+		{name: "full match", item: ring(item.QualityUnique, true, 30), expected: true},
+		{name: "stats not matching", item: ring(item.QualityUnique, true, 10), expected: false},
+		{name: "quality not matching", item: ring(item.QualityRare, true, 30), expected: false},
+		// Partial matches are not bought, stats can not be checked
+		{name: "unidentified", item: data.Item{ID: testRingID, Name: "Ring", Quality: item.QualityUnique}, expected: false},
+	}
+
+	for _, tt := range tests {
+		rule, found := IsShoppingItem(tt.item)
+		if found != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, found)
+		}
+		if found && rule.LineNumber != 1 {
+			t.Errorf("%s: expected the matching rule, got %+v", tt.name, rule)
+		}
+	}
+}
+
+func TestIsShoppingItemWithoutRules(t *testing.T) {
+	newShoppingTestContext(t)
+
+	if _, found := IsShoppingItem(data.Item{ID: testRingID, Name: "Ring", Quality: item.QualityUnique, Identified: true}); found {
+		t.Error("nothing should be bought without shopping rules")
+	}
+}
+
+func TestShoppingGoldReserveReached(t *testing.T) {
+	ctx := newShoppingTestContext(t)
+	ctx.CharacterCfg.Game.Shopping.MinGoldReserve = 50000
+
+	tests := []struct {
+		gold      int
+		stashGold int
+		expected  bool
+	}{
+		{gold: 10000, stashGold: 0, expected: true},
+		// Stashed gold counts, vendors take it from the stash when the inventory is not enough
+		{gold: 10000, stashGold: 40000, expected: false},
+		{gold: 60000, stashGold: 0, expected: false},
+	}
+
+	for _, tt := range tests {
+		ctx.Data.PlayerUnit.Stats = stat.Stats{{ID: stat.Gold, Value: tt.gold}, {ID: stat.StashGold, Value: tt.stashGold}}
+		if reached := shoppingGoldReserveReached(); reached != tt.expected {
+			t.Errorf("gold %d, stash gold %d: expected %v, got %v", tt.gold, tt.stashGold, tt.expected, reached)
+		}
+	}
+
+	ctx.CharacterCfg.Game.Shopping.MinGoldReserve = 0
+	ctx.Data.PlayerUnit.Stats = stat.Stats{}
+	if shoppingGoldReserveReached() {
+		t.Error("reserve should never be reached without a minimum")
+	}
+}
@@ -14,16 +14,19 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/object"
 	"github.com/hectorgimenez/d2go/pkg/nip"
 	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/sharedstash"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
 	"github.com/lxn/win"
 )
 
 const (
-	maxGoldPerStashTab = 2500000
+	maxGoldPerStashTab    = 2500000
+	recipeItemStashReason = "Item is part of a enabled recipe"
 )
 
 func Stash(forceStash bool) error {
@@ -152,6 +155,12 @@ func stashInventory(firstRun bool) {
 			SwitchStashTab(currentTab)
 		}
 
+		// Recipe ingredients go to the shared stash when another character of the account is doing the cubing
+		if matchedRule == recipeItemStashReason && currentTab < 2 && sharedstash.HasOtherCrafter(ctx.CharacterCfg.Username, ctx.Name) {
+			currentTab = 2
+			SwitchStashTab(currentTab)
+		}
+
 		for currentTab < 5 {
 			if stashItemAction(i, matchedRule, ruleFile, firstRun) {
 				r, res := ctx.CharacterCfg.Runtime.Rules.EvaluateAll(i)
@@ -201,7 +210,7 @@ func shouldStashIt(i data.Item, firstRun bool) (bool, string, string) {
 
 	// Stash items that are part of a recipe which are not covered by the NIP rules
 	if shouldKeepRecipeItem(i) {
-		return true, "Item is part of a enabled recipe", ""
+		return true, recipeItemStashReason, ""
 	}
 
 	// Don't stash the Tomes, keys and WirtsLeg
@@ -231,6 +240,12 @@ func shouldStashIt(i data.Item, firstRun bool) (bool, string, string) {
 	if res == nip.RuleResultFullMatch {
 		return true, rule.RawLine, rule.Filename + ":" + strconv.Itoa(rule.LineNumber)
 	}
+
+	// Items bought from vendors may not be covered by the pickit rules
+	if rule, isShoppingItem := IsShoppingItem(i); isShoppingItem {
+		return true, rule.RawLine, rule.Filename + ":" + strconv.Itoa(rule.LineNumber)
+	}
+
 	return false, "", ""
 }
 
@@ -255,11 +270,26 @@ func shouldKeepRecipeItem(i data.Item) bool {
 		}
 	}
 
+	// Magic bases for crafting recipes
+	if isCraftingBase(i) {
+		return true
+	}
+
 	recipeMatch := false
 
 	// Check if the item is part of a recipe and if that recipe is enabled
-	for _, recipe := range Recipes {
-		if slices.Contains(recipe.Items, string(i.Name)) && slices.Contains(ctx.CharacterCfg.CubeRecipes.EnabledRecipes, recipe.Name) {
+	enabled := enabledRecipeNames(ctx)
+	for _, recipe := range availableCubeRecipes() {
+		if !slices.Contains(enabled, recipe.Name) {
+			continue
+		}
+
+		if slices.Contains(recipe.Items, string(i.Name)) {
+			recipeMatch = true
+			break
+		}
+
+		if recipe.Definition != nil && slices.ContainsFunc(recipe.Definition.Inputs, func(in config.CubeRecipeInput) bool { return in.Matches(i) }) {
 			recipeMatch = true
 			break
 		}
@@ -3,6 +3,7 @@ package step
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -35,6 +36,7 @@ type attackSettings struct {
 	numOfAttacks     int           // Number of attacks to perform
 	timeout          time.Duration // Timeout for the attack sequence
 	isBurstCastSkill bool          // Whether this is a channeled/burst skill like Nova
+	kite             bool          // Whether to move away from enemies getting too close
 }
 
 // AttackOption defines a function type for configuring attack settings
@@ -82,6 +84,13 @@ func EnsureAura(aura skill.ID) AttackOption {
 	}
 }
 
+// Kite keeps the enemies at the distance defined in the character config, it does nothing if kiting is disabled
+func Kite() AttackOption {
+	return func(step *attackSettings) {
+		step.kite = context.Get().CharacterCfg.Character.Kiting.Enabled
+	}
+}
+
 // PrimaryAttack initiates a primary (left-click) attack sequence
 func PrimaryAttack(target data.UnitID, numOfAttacks int, standStill bool, opts ...AttackOption) error {
 	ctx := context.Get()
@@ -184,6 +193,12 @@ func attack(settings attackSettings) error {
 		needsRepositioning := !state.failedAttemptStartTime.IsZero() &&
 			time.Since(state.failedAttemptStartTime) > 3*time.Second
 
+		if settings.kite {
+			if err := kite(ctx); err != nil {
+				ctx.Logger.Debug("Kiting failed", slog.String("error", err.Error()))
+			}
+		}
+
 		// Be sure we stay in range of the enemy
 		err := ensureEnemyIsInRange(monster, settings.maxDistance, settings.minDistance, needsRepositioning)
 		if err != nil {
@@ -231,16 +246,16 @@ func burstAttack(settings attackSettings) error {
 			return nil // Timeout reached, finish attack sequence
 		}
 
-		target := data.Monster{}
+		monstersInRange := make([]data.Monster, 0)
 		for _, monster = range ctx.Data.Monsters.Enemies() {
 			distance := ctx.PathFinder.DistanceFromMe(monster.Position)
 			if isValidEnemy(monster, ctx) && distance <= settings.maxDistance {
-				target = monster
-				break
+				monstersInRange = append(monstersInRange, monster)
 			}
 		}
 
-		if target.UnitID == 0 {
+		target, found := ctx.Data.BestTarget(monstersInRange, ctx.IsImmuneToCharacter)
+		if !found {
 			return nil // We have no valid targets in range, finish attack sequence
 		}
 
@@ -249,6 +264,12 @@ func burstAttack(settings attackSettings) error {
 		needsRepositioning := !state.failedAttemptStartTime.IsZero() &&
 			time.Since(state.failedAttemptStartTime) > 3*time.Second
 
+		if settings.kite {
+			if err = kite(ctx); err != nil {
+				ctx.Logger.Debug("Kiting failed", slog.String("error", err.Error()))
+			}
+		}
+
 		// If we don't have LoS we will need to interrupt and move :(
 		if !ctx.PathFinder.LineOfSight(ctx.Data.PlayerUnit.Position, target.Position) || needsRepositioning {
 			err = ensureEnemyIsInRange(target, settings.maxDistance, settings.minDistance, needsRepositioning)
@@ -294,7 +315,7 @@ func ensureEnemyIsInRange(monster data.Monster, maxDistance, minDistance int, ne
 	ctx := context.Get()
 	ctx.SetLastStep("ensureEnemyIsInRange")
 
-	// TODO: Add an option for telestomp based on the char configuration and kite
+	// TODO: Add an option for telestomp based on the char configuration
 	currentPos := ctx.Data.PlayerUnit.Position
 	distanceToMonster := ctx.PathFinder.DistanceFromMe(monster.Position)
 	hasLoS := ctx.PathFinder.LineOfSight(currentPos, monster.Position)
@@ -326,7 +347,10 @@ func ensureEnemyIsInRange(monster data.Monster, maxDistance, minDistance int, ne
 		return errors.New("path could not be calculated")
 	}
 
-	// Look for suitable position along path
+	// Look for suitable position along path, picking the one with less threat around
+	threats := ctx.PathFinder.Threats()
+	var bestDest *data.Position
+	bestThreat := 0
 	for _, pos := range path {
 		monsterDistance := utils.DistanceFromPoint(ctx.Data.AreaData.RelativePosition(monster.Position), pos)
 		if monsterDistance > maxDistance || monsterDistance < minDistance {
@@ -344,9 +368,24 @@ func ensureEnemyIsInRange(monster data.Monster, maxDistance, minDistance int, ne
 			dest = ctx.PathFinder.BeyondPosition(currentPos, dest, 9)
 		}
 
-		if ctx.PathFinder.LineOfSight(dest, monster.Position) {
-			return MoveTo(dest)
+		if !ctx.PathFinder.LineOfSight(dest, monster.Position) {
+			continue
+		}
+
+		threat := threats.At(dest)
+		if bestDest == nil || threat < bestThreat {
+			bestDest = &dest
+			bestThreat = threat
 		}
+
+		// Safe position, no need to keep looking
+		if threat == 0 {
+			break
+		}
+	}
+
+	if bestDest != nil {
+		return MoveTo(*bestDest)
 	}
 
 	return nil
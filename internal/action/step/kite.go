@@ -0,0 +1,46 @@
+package step
+
+import (
+	"errors"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/pather"
+)
+
+// kite moves the character away when a melee enemy gets closer than the configured minimum distance, ranged enemies
+// are ignored since moving away from them doesn't help
+func kite(ctx *context.Status) error {
+	ctx.SetLastStep("Kite")
+
+	cfg := ctx.CharacterCfg.Character.Kiting
+
+	tooClose := false
+	enemies := make([]data.Position, 0)
+	for _, m := range ctx.Data.Monsters.Enemies() {
+		if m.Stats[stat.Life] <= 0 || !pather.IsMeleeAttacker(m) {
+			continue
+		}
+
+		distance := ctx.PathFinder.DistanceFromMe(m.Position)
+		if distance > cfg.MaxDistance*2 {
+			continue
+		}
+		if distance < cfg.MinDistance {
+			tooClose = true
+		}
+		enemies = append(enemies, m.Position)
+	}
+
+	if !tooClose {
+		return nil
+	}
+
+	dest, found := ctx.PathFinder.KitePosition(enemies, cfg.MinDistance, cfg.MaxDistance)
+	if !found {
+		return errors.New("no safe position found to kite")
+	}
+
+	return MoveTo(dest)
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/npc"
 	"github.com/hectorgimenez/koolo/internal/action/step"
 	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
 
@@ -15,7 +16,10 @@ func OpenTPIfLeader() error {
 	isLeader := ctx.CharacterCfg.Companion.Leader
 
 	if isLeader {
-		return step.OpenPortal()
+		if err := step.OpenPortal(); err != nil {
+			return err
+		}
+		event.Send(event.CompanionPortalOpened(event.Text(ctx.Name, "Leader portal opened"), ctx.Data.PlayerUnit.Area))
 	}
 
 	return nil
@@ -33,12 +33,18 @@ func PreRun(firstRun bool) error {
 	// Identify - either via Cain or Tome
 	IdentifyAll(false)
 
+	// Equip upgrades before they are stashed or sold
+	AutoEquip()
+
 	// Stash before vendor
 	Stash(false)
 
 	// Refill pots, sell, buy etc
 	VendorRefill(false, true)
 
+	// Equip anything bought from the vendor
+	AutoEquip()
+
 	// Gamble
 	Gamble()
 
@@ -50,8 +56,12 @@ func PreRun(firstRun bool) error {
 	// Leveling related checks
 	if ctx.CharacterCfg.Game.Leveling.EnsurePointsAllocation {
 		ResetStats()
-		EnsureStatPoints()
-		EnsureSkillPoints()
+		if err := EnsureStatPoints(); err != nil {
+			ctx.Logger.Warn("Failed allocating stat points", "error", err)
+		}
+		if err := EnsureSkillPoints(); err != nil {
+			ctx.Logger.Warn("Failed allocating skill points", "error", err)
+		}
 	}
 
 	if ctx.CharacterCfg.Game.Leveling.EnsureKeyBinding {
@@ -87,16 +97,22 @@ func InRunReturnTownRoutine() error {
 	}
 
 	IdentifyAll(false)
+	AutoEquip()
 
 	VendorRefill(false, true)
+	AutoEquip()
 	Stash(false)
 	Gamble()
 	Stash(false)
 	CubeRecipes()
 
 	if ctx.CharacterCfg.Game.Leveling.EnsurePointsAllocation {
-		EnsureStatPoints()
-		EnsureSkillPoints()
+		if err := EnsureStatPoints(); err != nil {
+			ctx.Logger.Warn("Failed allocating stat points", "error", err)
+		}
+		if err := EnsureSkillPoints(); err != nil {
+			ctx.Logger.Warn("Failed allocating skill points", "error", err)
+		}
 	}
 
 	if ctx.CharacterCfg.Game.Leveling.EnsureKeyBinding {
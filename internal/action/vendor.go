@@ -6,8 +6,11 @@ import (
 	"github.com/hectorgimenez/koolo/internal/action/step"
 	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/town"
+	"github.com/hectorgimenez/koolo/internal/ui"
+	"github.com/hectorgimenez/koolo/internal/utils"
 	"github.com/lxn/win"
 
+	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/item"
 	"github.com/hectorgimenez/d2go/pkg/data/npc"
 )
@@ -49,6 +52,14 @@ func VendorRefill(forceRefill, sellJunk bool) error {
 		town.SellJunk()
 	}
 
+	if _, isLevelingChar := ctx.Char.(context.LevelingCharacter); isLevelingChar {
+		buyGearUpgrades()
+	}
+
+	if ctx.CharacterCfg.Game.Shopping.Enabled {
+		ShopVendorItems(vendorNPC)
+	}
+
 	return step.CloseAllMenus()
 }
 
@@ -81,6 +92,28 @@ func BuyAtVendor(vendor npc.ID, items ...VendorItemRequest) error {
 	return step.CloseAllMenus()
 }
 
+// buyVendorItem looks for the item in the given vendor tabs and buys it. Items from every tab are read at the same
+// time and the tab they belong to is not known, so the item is hovered before buying it, otherwise we could buy
+// whatever is in the same position of the current tab.
+func buyVendorItem(i data.Item, quantity int, tabs ...int) bool {
+	ctx := context.Get()
+
+	screenPos := ui.GetScreenCoordsForItem(i)
+	for _, tab := range tabs {
+		SwitchStashTab(tab)
+		ctx.HID.MovePointer(screenPos.X, screenPos.Y)
+		utils.Sleep(200)
+		ctx.RefreshGameData()
+
+		if ctx.Data.HoverData.UnitID == i.UnitID {
+			town.BuyItem(i, quantity)
+			return true
+		}
+	}
+
+	return false
+}
+
 type VendorItemRequest struct {
 	Item     item.Name
 	Quantity int
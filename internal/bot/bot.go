@@ -2,15 +2,14 @@ package bot
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/koolo/internal/action"
+	"github.com/hectorgimenez/koolo/internal/config"
 	botCtx "github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/event"
-	"github.com/hectorgimenez/koolo/internal/health"
 	"github.com/hectorgimenez/koolo/internal/run"
 	"golang.org/x/sync/errgroup"
 )
@@ -97,6 +96,22 @@ func (b *Bot) Run(ctx context.Context, firstRun bool, runs []run.Run) error {
 			}
 		}
 	})
+	// Watchdog finishes the game if the bot is stuck and it can not be recovered. Followers wait for the leader
+	// without doing anything, so it's not used for them.
+	var recoveries chan watchdogRecovery
+	if b.ctx.CharacterCfg.Watchdog.Enabled && !(b.ctx.CharacterCfg.Companion.Enabled && !b.ctx.CharacterCfg.Companion.Leader) {
+		recoveries = make(chan watchdogRecovery)
+		g.Go(func() error {
+			defer func() {
+				cancel()
+				b.Stop()
+				recover()
+			}()
+
+			return b.watchdog(ctx, recoveries)
+		})
+	}
+
 	// High priority loop, this will interrupt (pause) low priority loop
 	g.Go(func() error {
 		defer func() {
@@ -182,6 +197,13 @@ func (b *Bot) Run(ctx context.Context, firstRun bool, runs []run.Run) error {
 						time.Sleep(500 * time.Millisecond)
 					}
 				}
+
+				// Watchdog recoveries are executed here, so normal priority loop is paused until they finish
+				select {
+				case r := <-recoveries:
+					r.done <- r.fn()
+				default:
+				}
 				b.ctx.SwitchPriority(botCtx.PriorityNormal)
 			}
 		}
@@ -201,37 +223,43 @@ func (b *Bot) Run(ctx context.Context, firstRun bool, runs []run.Run) error {
 			case <-ctx.Done():
 				return nil
 			default:
-				event.Send(event.RunStarted(event.Text(b.ctx.Name, fmt.Sprintf("Starting run: %s", r.Name())), r.Name()))
+			}
+
+			policy := b.ctx.CharacterCfg.RunPolicy(r.Name())
+			skipped := false
+			for attempt := 1; ; attempt++ {
+				event.Send(event.RunStarted(event.Text(b.ctx.Name, fmt.Sprintf("Starting run: %s", r.Name())), r.Name(), attempt))
 				err = action.PreRun(firstRun)
 				if err != nil {
 					return err
 				}
 
 				firstRun = false
-				err = r.Run()
+				err = b.executeRun(r, time.Duration(policy.MaxDuration)*time.Second)
 
-				var runFinishReason event.FinishReason
-				if err != nil {
-					switch {
-					case errors.Is(err, health.ErrChicken):
-						runFinishReason = event.FinishedChicken
-					case errors.Is(err, health.ErrMercChicken):
-						runFinishReason = event.FinishedMercChicken
-					case errors.Is(err, health.ErrDied):
-						runFinishReason = event.FinishedDied
-					default:
-						runFinishReason = event.FinishedError
-					}
-				} else {
-					runFinishReason = event.FinishedOK
+				event.Send(event.RunFinished(event.Text(b.ctx.Name, fmt.Sprintf("Finished run: %s", r.Name())), r.Name(), runFinishReason(err), attempt))
+
+				if err == nil {
+					break
 				}
 
-				event.Send(event.RunFinished(event.Text(b.ctx.Name, fmt.Sprintf("Finished run: %s", r.Name())), r.Name(), runFinishReason))
+				onError := onRunError(err, policy, attempt)
+				if onError == config.RunOnErrorEndGame || ctx.Err() != nil {
+					return err
+				}
 
-				if err != nil {
+				b.ctx.Logger.Warn("Run failed, game continues", "run", r.Name(), "attempt", attempt, "onError", onError, "error", err)
+				if err = action.ReturnTown(); err != nil {
 					return err
 				}
 
+				if onError == config.RunOnErrorSkip {
+					skipped = true
+					break
+				}
+			}
+
+			if !skipped {
 				err = action.PostRun(r == runs[len(runs)-1])
 				if err != nil {
 					return err
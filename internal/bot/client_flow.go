@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// clientFlow reads the game client screens and interacts with them for the out of game lifecycle
+type clientFlow struct {
+	supervisor *SinglePlayerSupervisor
+}
+
+func (f *clientFlow) InGame() bool {
+	return f.supervisor.bot.ctx.Manager.InGame()
+}
+
+func (f *clientFlow) IsLoading() bool {
+	f.supervisor.bot.ctx.RefreshGameData()
+
+	return f.supervisor.bot.ctx.Data.OpenMenus.LoadingScreen
+}
+
+func (f *clientFlow) IsInCharacterSelectionScreen() bool {
+	return f.supervisor.bot.ctx.GameReader.IsInCharacterSelectionScreen()
+}
+
+func (f *clientFlow) IsInLobby() bool {
+	return f.supervisor.bot.ctx.GameReader.IsInLobby()
+}
+
+func (f *clientFlow) IsOnline() bool {
+	return f.supervisor.bot.ctx.GameReader.IsOnline()
+}
+
+func (f *clientFlow) ModalMessage() (bool, string) {
+	return f.supervisor.bot.ctx.GameReader.IsDismissableModalPresent()
+}
+
+func (f *clientFlow) DismissModal() error {
+	f.supervisor.bot.ctx.HID.PressKey(0x1B) // ESC
+
+	return nil
+}
+
+func (f *clientFlow) Reconnect() error {
+	// Click the online tab to re-connect to bnet
+	f.supervisor.bot.ctx.HID.Click(game.LeftButton, 1090, 32)
+
+	return nil
+}
+
+func (f *clientFlow) EnterLobby() error {
+	f.supervisor.bot.ctx.HID.Click(game.LeftButton, 744, 650)
+
+	return nil
+}
+
+func (f *clientFlow) LeaveLobby() error {
+	f.supervisor.bot.ctx.HID.PressKey(0x1B) // ESC - to avoid importing win here as well
+
+	return nil
+}
+
+func (f *clientFlow) CreateGame() error {
+	return f.supervisor.bot.ctx.Manager.NewGame()
+}
+
+func (f *clientFlow) CreateLobbyGame() error {
+	_, _, err := f.supervisor.bot.ctx.Manager.CreateOnlineGame()
+
+	return err
+}
+
+func (f *clientFlow) JoinLobbyGame() error {
+	return f.supervisor.joinPublicGame()
+}
+
+func (f *clientFlow) ExitGame() error {
+	return f.supervisor.bot.ctx.Manager.ExitGame()
+}
+
+func (f *clientFlow) KillClient() error {
+	return f.supervisor.KillClient()
+}
@@ -1,122 +1,427 @@
 package bot
 
-// TODO companion sueprvisor
-//
-//import (
-//	"context"
-//	"errors"
-//	"fmt"
-//	"log/slog"
-//	"time"
-//
-//	"github.com/hectorgimenez/koolo/internal/action"
-//
-//	"github.com/hectorgimenez/koolo/internal/container"
-//
-//	"github.com/hectorgimenez/koolo/internal/config"
-//	"github.com/hectorgimenez/koolo/internal/event"
-//	"github.com/hectorgimenez/koolo/internal/helper"
-//	"github.com/hectorgimenez/koolo/internal/run"
-//)
-//
-//type CompanionSupervisor struct {
-//	*baseSupervisor
-//}
-//
-//func NewCompanionSupervisor(name string, bot *Bot, runFactory *run.Factory, statsHandler *StatsHandler, c container.Container, pid uint32, hwnd uintptr) (*CompanionSupervisor, error) {
-//	bs, err := newBaseSupervisor(bot, runFactory, name, statsHandler, c)
-//	if err != nil {
-//		return nil, err
-//	}
-//
-//	return &CompanionSupervisor{
-//		baseSupervisor: bs,
-//	}, nil
-//}
-//
-//// Start will return error if it can not be started, otherwise will always return nil
-//func (s *CompanionSupervisor) Start() error {
-//	ctx, cancel := context.WithCancel(context.Background())
-//	s.cancelFn = cancel
-//
-//	err := s.ensureProcessIsRunningAndPrepare(ctx)
-//	if err != nil {
-//		return fmt.Errorf("error preparing game: %w", err)
-//	}
-//
-//	gameCounter := 0
-//	firstRun := true
-//	err = s.waitUntilCharacterSelectionScreen()
-//	if err != nil {
-//		return fmt.Errorf("error waiting for character selection screen: %w", err)
-//	}
-//
-//	for {
-//		select {
-//		case <-ctx.Done():
-//			return nil
-//		default:
-//			if s.c.CharacterCfg.Companion.Leader {
-//				time.Sleep(time.Second * 5)
-//				gameName, err := s.c.Manager.CreateOnlineGame(gameCounter)
-//				gameCounter++ // Sometimes game is created but error during join, so game name will be in use
-//				if err != nil {
-//					s.c.Logger.Error(fmt.Sprintf("Error creating new game: %s", err.Error()))
-//					continue
-//				}
-//
-//				if config.Koolo.Discord.EnableGameCreatedMessages {
-//					event.Send(event.GameCreated(event.Text(s.name, "New game created: %s"), gameName, config.Characters[s.name].Companion.GamePassword))
-//				} else {
-//					event.Send(event.GameCreated(event.Text(s.name, ""), gameName, config.Characters[s.name].Companion.GamePassword))
-//				}
-//				err = s.startBot(ctx, s.runFactory.BuildRuns(), firstRun)
-//				if err != nil {
-//					return err
-//				}
-//				action.ResetBuffTime(s.Name())
-//				firstRun = false
-//			} else {
-//				s.c.Logger.Debug("Waiting for new game to be created...")
-//				evt := s.c.EventListener.WaitForEvent(ctx)
-//				if gcEvent, ok := evt.(event.GameCreatedEvent); ok && gcEvent.Name != "" {
-//					err = s.c.Manager.JoinOnlineGame(gcEvent.Name, gcEvent.Password)
-//					if err != nil {
-//						s.c.Logger.Error(err.Error())
-//						continue
-//					}
-//
-//					runs := s.runFactory.BuildRuns()
-//					err = s.startBot(ctx, runs, firstRun)
-//					firstRun = false
-//					if err != nil {
-//						return err
-//					}
-//					action.ResetBuffTime(s.Name())
-//				}
-//			}
-//		}
-//	}
-//}
-//
-//func (s *CompanionSupervisor) startBot(ctx context.Context, runs []run.Run, firstRun bool) error {
-//	gameStart := time.Now()
-//	s.logGameStart(runs)
-//	err := s.bot.Run(ctx, firstRun, runs)
-//	if err != nil {
-//		if errors.Is(context.Canceled, ctx.Err()) {
-//			return nil
-//		}
-//		errorMsg := fmt.Sprintf("Game finished with errors, reason: %s. Game total time: %0.2fs", err.Error(), time.Since(gameStart).Seconds())
-//		event.Send(event.GameFinished(event.WithScreenshot(s.name, errorMsg, s.c.Reader.Screenshot()), event.FinishedError))
-//		s.c.Logger.Warn(errorMsg, slog.String("supervisor", s.name))
-//	}
-//	if exitErr := s.c.Manager.ExitGame(); exitErr != nil {
-//		return fmt.Errorf("error exiting game: %s", exitErr)
-//	}
-//	firstRun = false
-//
-//	helper.Sleep(5000)
-//
-//	return nil
-//}
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/koolo/internal/config"
+	ct "github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/health"
+	"github.com/hectorgimenez/koolo/internal/party"
+	"github.com/hectorgimenez/koolo/internal/run"
+	"github.com/hectorgimenez/koolo/internal/utils"
+)
+
+type companionGame struct {
+	name     string
+	password string
+	// finished is set when the leader left the game
+	finished bool
+}
+
+// companionHub forwards the games created and finished by the leaders to their followers running in the same
+// process, a channel per follower is enough. Followers running in other machines are connected to the party server.
+type companionHub struct {
+	mu        sync.Mutex
+	followers map[string]chan companionGame
+	remote    *party.Server
+}
+
+var companions = &companionHub{followers: make(map[string]chan companionGame)}
+
+func (h *companionHub) register(follower string) <-chan companionGame {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan companionGame, 10)
+	h.followers[follower] = ch
+
+	return ch
+}
+
+func (h *companionHub) unregister(follower string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.followers, follower)
+}
+
+// Handle is registered in the event listener, it never blocks, followers not reading the channel will lose messages
+func (h *companionHub) Handle(_ context.Context, e event.Event) error {
+	leaderCfg, found := config.Characters[e.Supervisor()]
+	if !found || !leaderCfg.Companion.Enabled || !leaderCfg.Companion.Leader {
+		return nil
+	}
+
+	var msg companionGame
+	remoteMsg := party.Message{Party: e.Supervisor(), From: leaderCfg.CharacterName}
+	switch evt := e.(type) {
+	case event.GameCreatedEvent:
+		if evt.Name == "" {
+			return nil
+		}
+		msg = companionGame{name: evt.Name, password: evt.Password}
+		remoteMsg.Type = party.MessageGameCreated
+		remoteMsg.Game = evt.Name
+		remoteMsg.Password = evt.Password
+	case event.GameFinishedEvent:
+		msg = companionGame{finished: true}
+		remoteMsg.Type = party.MessageLeaving
+	case event.CompanionPortalOpenedEvent:
+		// Local followers can see the portal in the game, no need to let them know
+		remoteMsg.Type = party.MessagePortal
+		remoteMsg.Area = evt.Area
+		if h.remote != nil {
+			h.remote.Publish(remoteMsg)
+		}
+		return nil
+	default:
+		return nil
+	}
+
+	if h.remote != nil {
+		h.remote.Publish(remoteMsg)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for follower, ch := range h.followers {
+		followerCfg, found := config.Characters[follower]
+		if !found {
+			continue
+		}
+
+		// Leader name can be the supervisor or the character name, character name is needed to find the portals
+		leaderName := followerCfg.Companion.LeaderName
+		if !strings.EqualFold(leaderName, e.Supervisor()) && !strings.EqualFold(leaderName, leaderCfg.CharacterName) {
+			continue
+		}
+
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// listenRemote logs the state of the followers connected from other machines
+func (h *companionHub) listenRemote(logger *slog.Logger) {
+	for msg := range h.remote.Messages() {
+		logger.Info("Party member update",
+			slog.String("party", msg.Party),
+			slog.String("member", msg.From),
+			slog.String("state", string(msg.Type)),
+			slog.String("game", msg.Game),
+		)
+	}
+}
+
+// CompanionSupervisor runs the followers, they join the games created by their leader and leave them with it.
+// Leaders are run by the SinglePlayerSupervisor.
+type CompanionSupervisor struct {
+	*baseSupervisor
+	games    <-chan companionGame
+	nextGame *companionGame
+	remote   atomic.Pointer[party.Client]
+}
+
+func (s *CompanionSupervisor) GetData() *game.Data {
+	return s.bot.ctx.Data
+}
+
+func (s *CompanionSupervisor) GetContext() *ct.Context {
+	return s.bot.ctx
+}
+
+func NewCompanionSupervisor(name string, bot *Bot, statsHandler *StatsHandler) (*CompanionSupervisor, error) {
+	bs, err := newBaseSupervisor(bot, name, statsHandler)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompanionSupervisor{
+		baseSupervisor: bs,
+	}, nil
+}
+
+// Start will return error if it can not be started, otherwise will always return nil
+func (s *CompanionSupervisor) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancelFn = cancel
+
+	err := s.ensureProcessIsRunningAndPrepare()
+	if err != nil {
+		return fmt.Errorf("error preparing game: %w", err)
+	}
+
+	if s.bot.ctx.CharacterCfg.Companion.LeaderAddress != "" {
+		s.games = s.connectRemoteLeader(ctx)
+	} else {
+		s.games = companions.register(s.name)
+		defer companions.unregister(s.name)
+	}
+
+	err = s.waitUntilCharacterSelectionScreen()
+	if err != nil {
+		return fmt.Errorf("error waiting for character selection screen: %w", err)
+	}
+
+	firstRun := true
+	var currentGame companionGame
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		g, found := s.waitForLeaderGame(ctx)
+		if !found {
+			return nil
+		}
+		currentGame = g
+
+		if err = s.enterLobby(); err != nil {
+			s.bot.ctx.Logger.Error(err.Error())
+			continue
+		}
+
+		s.bot.ctx.Logger.Info("Joining leader game", slog.String("game", g.name))
+		if err = s.bot.ctx.Manager.JoinOnlineGame(g.name, g.password); err != nil {
+			s.bot.ctx.Logger.Error(fmt.Sprintf("Error joining game %s: %s", g.name, err.Error()))
+			continue
+		}
+		s.sendPartyMessage(party.Message{Type: party.MessageJoined, Game: g.name})
+
+		leaderLeft, err := s.startBot(ctx, firstRun)
+		if err != nil {
+			return err
+		}
+		firstRun = false
+
+		// We left the game before the leader (chicken, death...), let's join it again
+		if !leaderLeft && s.nextGame == nil {
+			s.nextGame = &currentGame
+		}
+	}
+}
+
+// waitForLeaderGame blocks until the leader creates a new game, returns false if the supervisor was stopped
+func (s *CompanionSupervisor) waitForLeaderGame(ctx context.Context) (companionGame, bool) {
+	// Check if the leader finished or created a new game while we were leaving the previous one
+	for s.nextGame != nil {
+		select {
+		case g := <-s.games:
+			if g.finished {
+				s.nextGame = nil
+			} else {
+				s.nextGame = &g
+			}
+		default:
+			g := *s.nextGame
+			s.nextGame = nil
+			return g, true
+		}
+	}
+
+	s.bot.ctx.Logger.Info("Waiting for the leader to create a new game...")
+	s.sendPartyMessage(party.Message{Type: party.MessageReady})
+	for {
+		select {
+		case <-ctx.Done():
+			return companionGame{}, false
+		case g := <-s.games:
+			if !g.finished {
+				return g, true
+			}
+		}
+	}
+}
+
+// watchLeader cancels the game when the leader leaves it, if the leader already created a new one we keep it for later
+func (s *CompanionSupervisor) watchLeader(ctx context.Context, cancel context.CancelFunc) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case g := <-s.games:
+			if !g.finished {
+				s.nextGame = &g
+			}
+			s.bot.ctx.Logger.Info("Leader left the game, leaving too")
+			cancel()
+			return
+		}
+	}
+}
+
+// startBot runs the game until it finishes, it returns true if the game was finished by the leader
+func (s *CompanionSupervisor) startBot(ctx context.Context, firstRun bool) (bool, error) {
+	runs := run.BuildRuns(s.bot.ctx.CharacterCfg)
+	gameStart := time.Now()
+	s.bot.ctx.LastBuffAt = time.Time{}
+	s.logGameStart(runs)
+	s.bot.ctx.RefreshGameData()
+
+	if firstRun {
+		missingKeybindings := s.bot.ctx.Char.CheckKeyBindings()
+		if len(missingKeybindings) > 0 {
+			var missingKeybindingsText = "Missing key binding for skill(s):"
+			for _, v := range missingKeybindings {
+				missingKeybindingsText += fmt.Sprintf("\n%s", skill.SkillNames[v])
+			}
+			missingKeybindingsText += "\nPlease bind the skills. Pausing bot..."
+
+			utils.ShowDialog("Missing keybindings for "+s.bot.ctx.Name, missingKeybindingsText)
+			s.TogglePause()
+		}
+	}
+
+	gameCtx, cancelGame := context.WithCancel(ctx)
+	defer cancelGame()
+
+	watcherDone := make(chan struct{})
+	go func() {
+		defer close(watcherDone)
+		s.watchLeader(gameCtx, cancelGame)
+	}()
+
+	err := s.bot.Run(gameCtx, firstRun, runs)
+	leaderLeft := gameCtx.Err() != nil && ctx.Err() == nil
+	cancelGame()
+	<-watcherDone
+
+	// Supervisor was stopped
+	if ctx.Err() != nil {
+		return false, nil
+	}
+
+	// Leaving because the leader finished the game is the expected way to finish for followers
+	if err != nil && !leaderLeft {
+		var gameFinishReason event.FinishReason
+		switch {
+		case errors.Is(err, health.ErrChicken):
+			gameFinishReason = event.FinishedChicken
+		case errors.Is(err, health.ErrMercChicken):
+			gameFinishReason = event.FinishedMercChicken
+		case errors.Is(err, health.ErrDied):
+			gameFinishReason = event.FinishedDied
+		default:
+			gameFinishReason = event.FinishedError
+		}
+		event.Send(event.GameFinished(event.WithScreenshot(s.name, err.Error(), s.bot.ctx.GameReader.Screenshot()), gameFinishReason))
+		s.bot.ctx.Logger.Warn(
+			fmt.Sprintf("Game finished with errors, reason: %s. Game total time: %0.2fs", err.Error(), time.Since(gameStart).Seconds()),
+			slog.String("supervisor", s.name),
+		)
+	} else {
+		event.Send(event.GameFinished(event.Text(s.name, "Game finished successfully"), event.FinishedOK))
+	}
+
+	if exitErr := s.bot.ctx.Manager.ExitGame(); exitErr != nil {
+		errMsg := fmt.Sprintf("Error exiting game %s", exitErr.Error())
+		event.Send(event.GameFinished(event.WithScreenshot(s.name, errMsg, s.bot.ctx.GameReader.Screenshot()), event.FinishedError))
+		return leaderLeft, errors.New(errMsg)
+	}
+	s.sendPartyMessage(party.Message{Type: party.MessageLeaving})
+
+	utils.Sleep(2000)
+
+	return leaderLeft, nil
+}
+
+// connectRemoteLeader keeps a connection to the leader koolo instance, messages are forwarded the same way it's done
+// for the leaders running in this process
+func (s *CompanionSupervisor) connectRemoteLeader(ctx context.Context) <-chan companionGame {
+	games := make(chan companionGame, 10)
+	address := s.bot.ctx.CharacterCfg.Companion.LeaderAddress
+	name := s.bot.ctx.CharacterCfg.CharacterName
+	if name == "" {
+		name = s.name
+	}
+
+	go func() {
+		for ctx.Err() == nil {
+			client, err := party.Dial(ctx, address, config.Koolo.Party.Secret, s.bot.ctx.CharacterCfg.Companion.LeaderName, name)
+			if err != nil {
+				s.bot.ctx.Logger.Warn("Error connecting to the leader, retrying in 5 seconds", slog.Any("error", err))
+				select {
+				case <-ctx.Done():
+				case <-time.After(5 * time.Second):
+				}
+				continue
+			}
+
+			s.bot.ctx.Logger.Info("Connected to the leader", slog.String("address", address))
+			s.remote.Store(client)
+			stop := context.AfterFunc(ctx, func() { client.Close() })
+
+			for msg := range client.Messages() {
+				var g companionGame
+				switch msg.Type {
+				case party.MessageGameCreated:
+					g = companionGame{name: msg.Game, password: msg.Password}
+				case party.MessageLeaving:
+					g = companionGame{finished: true}
+				case party.MessagePortal:
+					s.bot.ctx.Logger.Debug("Leader portal is up", slog.String("area", msg.Area.Area().Name))
+					continue
+				default:
+					continue
+				}
+
+				select {
+				case games <- g:
+				default:
+				}
+			}
+
+			stop()
+			s.remote.Store(nil)
+			s.bot.ctx.Logger.Warn("Disconnected from the leader", slog.String("address", address))
+		}
+	}()
+
+	return games
+}
+
+// sendPartyMessage lets the remote leader know our state, it does nothing when the leader is in this process
+func (s *CompanionSupervisor) sendPartyMessage(msg party.Message) {
+	client := s.remote.Load()
+	if client == nil {
+		return
+	}
+
+	if err := client.Send(msg); err != nil {
+		s.bot.ctx.Logger.Warn("Error sending message to the leader", slog.Any("error", err))
+	}
+}
+
+func (s *CompanionSupervisor) enterLobby() error {
+	for range 5 {
+		if s.bot.ctx.GameReader.IsInLobby() {
+			return nil
+		}
+
+		s.bot.ctx.HID.Click(game.LeftButton, 744, 650)
+		utils.Sleep(1000)
+	}
+
+	if !s.bot.ctx.GameReader.IsInLobby() {
+		return errors.New("failed to enter bnet lobby after 5 retries")
+	}
+
+	return nil
+}
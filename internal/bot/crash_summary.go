@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	ct "github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/utils"
+)
+
+// crashInfo is collected while the client is running, it can not be read from memory once the client crashed
+type crashInfo struct {
+	mapSeed      uint
+	screenshot   image.Image
+	screenshotAt time.Time
+}
+
+type crashSummary struct {
+	Supervisor         string               `json:"supervisor"`
+	CrashedAt          time.Time            `json:"crashedAt"`
+	ConsecutiveCrashes int                  `json:"consecutiveCrashes"`
+	MapSeed            uint                 `json:"mapSeed"`
+	Debug              map[string]*ct.Debug `json:"debug"`
+	Screenshot         string               `json:"screenshot,omitempty"`
+	// ScreenshotTakenAt can be some minutes before the crash, the client can not be captured once it crashed
+	ScreenshotTakenAt *time.Time `json:"screenshotTakenAt,omitempty"`
+}
+
+// writeCrashSummary saves the last known state of the supervisor, so crashes can be diagnosed later, returns the path
+func writeCrashSummary(supervisorName string, ctx *ct.Context, info crashInfo, consecutiveCrashes int) (string, error) {
+	dir := config.Koolo.LogSaveDirectory
+	if dir == "" {
+		dir = "logs"
+	}
+	dir = filepath.Join(dir, "crashes")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("error creating crashes directory: %w", err)
+	}
+
+	summary := crashSummary{
+		Supervisor:         supervisorName,
+		CrashedAt:          time.Now(),
+		ConsecutiveCrashes: consecutiveCrashes,
+		MapSeed:            info.mapSeed,
+	}
+	if ctx != nil {
+		summary.Debug = map[string]*ct.Debug{
+			"high":       ctx.ContextDebug[ct.PriorityHigh],
+			"normal":     ctx.ContextDebug[ct.PriorityNormal],
+			"background": ctx.ContextDebug[ct.PriorityBackground],
+		}
+	}
+
+	fileName := fmt.Sprintf("Crash-%s-%s", supervisorName, summary.CrashedAt.Format("2006-01-02-15-04-05"))
+	if info.screenshot != nil {
+		summary.Screenshot = fileName + ".jpeg"
+		if !info.screenshotAt.IsZero() {
+			summary.ScreenshotTakenAt = &info.screenshotAt
+		}
+		if err := utils.SaveImageJPEG(info.screenshot, filepath.Join(dir, summary.Screenshot)); err != nil {
+			summary.Screenshot = ""
+		}
+	}
+
+	d, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fileName+".json")
+	if err = os.WriteFile(path, d, 0644); err != nil {
+		return "", fmt.Errorf("error writing crash summary: %w", err)
+	}
+
+	return path, nil
+}
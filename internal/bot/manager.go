@@ -1,9 +1,11 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strconv"
+	"sync"
 	"syscall"
 	"time"
 	"unsafe"
@@ -11,10 +13,11 @@ import (
 	"github.com/hectorgimenez/koolo/cmd/koolo/log"
 	"github.com/hectorgimenez/koolo/internal/character"
 	"github.com/hectorgimenez/koolo/internal/config"
-	"github.com/hectorgimenez/koolo/internal/context"
+	ct "github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/health"
+	"github.com/hectorgimenez/koolo/internal/party"
 	"github.com/hectorgimenez/koolo/internal/pather"
 	"github.com/hectorgimenez/koolo/internal/utils"
 	"github.com/hectorgimenez/koolo/internal/utils/winproc"
@@ -22,18 +25,122 @@ import (
 )
 
 type SupervisorManager struct {
-	logger         *slog.Logger
-	supervisors    map[string]Supervisor
-	crashDetectors map[string]*game.CrashDetector
-	eventListener  *event.Listener
+	logger          *slog.Logger
+	mu              sync.RWMutex // supervisors and crashDetectors are used from the web server, crash detectors and restarts
+	supervisors     map[string]Supervisor
+	crashDetectors  map[string]*game.CrashDetector
+	eventListener   *event.Listener
+	crashMu         sync.Mutex // restartPolicies and crashInfo are used from the event listener and crash detectors
+	restartPolicies map[string]*restartPolicy
+	crashInfo       map[string]crashInfo
 }
 
 func NewSupervisorManager(logger *slog.Logger, eventListener *event.Listener) *SupervisorManager {
-	return &SupervisorManager{
-		logger:         logger,
-		supervisors:    make(map[string]Supervisor),
-		crashDetectors: make(map[string]*game.CrashDetector),
-		eventListener:  eventListener,
+	mng := &SupervisorManager{
+		logger:          logger,
+		supervisors:     make(map[string]Supervisor),
+		crashDetectors:  make(map[string]*game.CrashDetector),
+		eventListener:   eventListener,
+		restartPolicies: make(map[string]*restartPolicy),
+		crashInfo:       make(map[string]crashInfo),
+	}
+	eventListener.Register(mng.handleEvent)
+	companions.remote = party.NewServer(logger, func() string { return config.Koolo.Party.Secret })
+	go companions.listenRemote(logger)
+	eventListener.Register(companions.Handle)
+
+	return mng
+}
+
+// PartyServer is served by the web server, followers running in other machines connect to it
+func (mng *SupervisorManager) PartyServer() *party.Server {
+	return companions.remote
+}
+
+func (mng *SupervisorManager) handleEvent(_ context.Context, e event.Event) error {
+	mng.updateCrashInfo(e)
+
+	switch evt := e.(type) {
+	case event.GameCreatedEvent:
+		// Client is working again, consecutive crashes are over
+		if p := mng.restartPolicy(evt.Supervisor()); p != nil {
+			p.reset()
+		}
+	case event.LevelingFinishedEvent:
+		mng.logger.Info("Leveling finished, restarting supervisor with the new profile", slog.String("supervisor", evt.Supervisor()), slog.String("class", evt.Class))
+		// Restart can not block the event listener, and it can not be done from the bot goroutine sending the event
+		go mng.restart(evt.Supervisor())
+	}
+
+	return nil
+}
+
+// updateCrashInfo keeps the last map seed and screenshot, they can not be read once the client crashed. Crashes are
+// detected once the process is gone, so the screenshot is taken on every run start to keep it as recent as possible.
+func (mng *SupervisorManager) updateCrashInfo(e event.Event) {
+	mng.crashMu.Lock()
+	defer mng.crashMu.Unlock()
+
+	info := mng.crashInfo[e.Supervisor()]
+	if e.Image() != nil {
+		info.screenshot = e.Image()
+		info.screenshotAt = e.OccurredAt()
+	}
+	switch e.(type) {
+	case event.GameCreatedEvent:
+		if ctx := mng.GetContext(e.Supervisor()); ctx != nil {
+			info.mapSeed = ctx.GameReader.MapSeed()
+		}
+	case event.RunStartedEvent:
+		if ctx := mng.GetContext(e.Supervisor()); ctx != nil && e.Image() == nil {
+			if img := ctx.GameReader.Screenshot(); img != nil {
+				info.screenshot = img
+				info.screenshotAt = time.Now()
+			}
+		}
+	}
+	mng.crashInfo[e.Supervisor()] = info
+}
+
+func (mng *SupervisorManager) restartPolicy(supervisorName string) *restartPolicy {
+	mng.crashMu.Lock()
+	defer mng.crashMu.Unlock()
+
+	return mng.restartPolicies[supervisorName]
+}
+
+func (mng *SupervisorManager) supervisor(supervisorName string) (Supervisor, bool) {
+	mng.mu.RLock()
+	defer mng.mu.RUnlock()
+
+	s, found := mng.supervisors[supervisorName]
+	return s, found
+}
+
+// runningSupervisors returns a copy, so supervisors can be used without holding the lock
+func (mng *SupervisorManager) runningSupervisors() map[string]Supervisor {
+	mng.mu.RLock()
+	defer mng.mu.RUnlock()
+
+	supervisors := make(map[string]Supervisor, len(mng.supervisors))
+	for name, s := range mng.supervisors {
+		supervisors[name] = s
+	}
+	return supervisors
+}
+
+// restart closes the game client and starts the supervisor again, loading the latest config
+func (mng *SupervisorManager) restart(supervisorName string) {
+	if s, found := mng.supervisor(supervisorName); found {
+		if sps, ok := s.(*SinglePlayerSupervisor); ok {
+			sps.KillClient()
+		}
+	}
+	mng.Stop(supervisorName)
+	time.Sleep(5 * time.Second) // Wait a bit before restarting
+
+	if err := mng.Start(supervisorName, false); err != nil {
+		mng.logger.Error("Error restarting supervisor", slog.String("supervisor", supervisorName), slog.Any("error", err))
 	}
 }
 
@@ -50,7 +157,7 @@ func (mng *SupervisorManager) AvailableSupervisors() []string {
 
 func (mng *SupervisorManager) Start(supervisorName string, attachToExisting bool, pidHwnd ...uint32) error {
 	// Avoid multiple instances of the supervisor - shitstorm prevention
-	if _, exists := mng.supervisors[supervisorName]; exists {
+	if _, exists := mng.supervisor(supervisorName); exists {
 		return fmt.Errorf("supervisor %s is already running", supervisorName)
 	}
 
@@ -83,12 +190,28 @@ func (mng *SupervisorManager) Start(supervisorName string, attachToExisting bool
 		return err
 	}
 
-	if oldCrashDetector, exists := mng.crashDetectors[supervisorName]; exists {
-		oldCrashDetector.Stop() // Stop the old crash detector if it exists
+	mng.mu.Lock()
+	if _, exists := mng.supervisors[supervisorName]; exists {
+		// Started from somewhere else while the game was starting
+		mng.mu.Unlock()
+		supervisor.Stop()
+		return fmt.Errorf("supervisor %s is already running", supervisorName)
 	}
-
+	oldCrashDetector, oldCrashDetectorExists := mng.crashDetectors[supervisorName]
 	mng.supervisors[supervisorName] = supervisor
 	mng.crashDetectors[supervisorName] = crashDetector
+	mng.mu.Unlock()
+
+	if oldCrashDetectorExists {
+		oldCrashDetector.Stop() // Stop the old crash detector if it exists
+	}
+
+	// Supervisor is started again after giving up, let's start from scratch
+	mng.crashMu.Lock()
+	if p, found := mng.restartPolicies[supervisorName]; !found || p.isCrashed() {
+		mng.restartPolicies[supervisorName] = newRestartPolicy(config.Characters[supervisorName])
+	}
+	mng.crashMu.Unlock()
 
 	if config.Koolo.GameWindowArrangement {
 		go func() {
@@ -118,7 +241,7 @@ func (mng *SupervisorManager) ReloadConfig() error {
 	}
 
 	// Apply new configs to running supervisors
-	for name, sup := range mng.supervisors {
+	for name, sup := range mng.runningSupervisors() {
 		newCfg, exists := config.Characters[name]
 		if !exists {
 			continue
@@ -141,61 +264,62 @@ func (mng *SupervisorManager) ReloadConfig() error {
 }
 
 func (mng *SupervisorManager) StopAll() {
-	for _, s := range mng.supervisors {
+	for _, s := range mng.runningSupervisors() {
 		s.Stop()
 	}
 }
 
 func (mng *SupervisorManager) Stop(supervisor string) {
-
+	mng.mu.Lock()
 	s, found := mng.supervisors[supervisor]
+	cd, cdFound := mng.crashDetectors[supervisor]
 	if found {
+		// Delete him from the list of Supervisors
+		delete(mng.supervisors, supervisor)
+		delete(mng.crashDetectors, supervisor)
+	}
+	mng.mu.Unlock()
 
+	if found {
 		// Stop the Supervisor
 		s.Stop()
 
-		// Delete him from the list of Supervisors
-		delete(mng.supervisors, supervisor)
-
-		if cd, ok := mng.crashDetectors[supervisor]; ok {
+		if cdFound {
 			cd.Stop()
-			delete(mng.crashDetectors, supervisor)
 		}
 	}
 }
 
 func (mng *SupervisorManager) TogglePause(supervisor string) {
-	s, found := mng.supervisors[supervisor]
+	s, found := mng.supervisor(supervisor)
 	if found {
 		s.TogglePause()
 	}
 }
 
 func (mng *SupervisorManager) Status(characterName string) Stats {
-	for name, supervisor := range mng.supervisors {
-		if name == characterName {
-			return supervisor.Stats()
-		}
+	if supervisor, found := mng.supervisor(characterName); found {
+		return supervisor.Stats()
+	}
+
+	if p := mng.restartPolicy(characterName); p != nil && p.isCrashed() {
+		return Stats{SupervisorStatus: Crashed}
 	}
 
 	return Stats{}
 }
 
 func (mng *SupervisorManager) GetData(characterName string) *game.Data {
-	for name, supervisor := range mng.supervisors {
-		if name == characterName {
-			return supervisor.GetData()
-		}
+	if supervisor, found := mng.supervisor(characterName); found {
+		return supervisor.GetData()
 	}
 
 	return nil
 }
 
-func (mng *SupervisorManager) GetContext(characterName string) *context.Context {
-	for name, supervisor := range mng.supervisors {
-		if name == characterName {
-			return supervisor.GetContext()
-		}
+func (mng *SupervisorManager) GetContext(characterName string) *ct.Context {
+	if supervisor, found := mng.supervisor(characterName); found {
+		return supervisor.GetContext()
 	}
 
 	return nil
@@ -235,7 +359,7 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 		return nil, nil, fmt.Errorf("error creating game injector: %w", err)
 	}
 
-	ctx := context.NewContext(supervisorName)
+	ctx := ct.NewContext(supervisorName)
 
 	hidM := game.NewHID(gr, gi)
 	pf := pather.NewPathFinder(gr, ctx.Data, hidM, cfg)
@@ -266,7 +390,12 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 
 	var supervisor Supervisor
 
-	supervisor, err = NewSinglePlayerSupervisor(supervisorName, bot, statsHandler)
+	// Leaders create their games as any other character, the companion hub lets their followers know about them
+	if cfg.Companion.Enabled && !cfg.Companion.Leader {
+		supervisor, err = NewCompanionSupervisor(supervisorName, bot, statsHandler)
+	} else {
+		supervisor, err = NewSinglePlayerSupervisor(supervisorName, bot, statsHandler)
+	}
 
 	if err != nil {
 		return nil, nil, err
@@ -275,9 +404,32 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 
 	// This function will be used to restart the client - passed to the crashDetector
 	restartFunc := func() {
-		mng.logger.Info("Restarting supervisor after crash", slog.String("supervisor", supervisorName))
+		policy := mng.restartPolicy(supervisorName)
+		delay, restart := policy.next()
+
+		mng.crashMu.Lock()
+		info := mng.crashInfo[supervisorName]
+		mng.crashMu.Unlock()
+		summaryPath, err := writeCrashSummary(supervisorName, mng.GetContext(supervisorName), info, policy.consecutiveCrashes())
+		if err != nil {
+			mng.logger.Error("Error writing crash summary", slog.String("supervisor", supervisorName), slog.Any("error", err))
+		}
+
 		mng.Stop(supervisorName)
-		time.Sleep(5 * time.Second) // Wait a bit before restarting
+		if !restart {
+			msg := fmt.Sprintf("Client crashed %d times in a row, supervisor won't be restarted. Crash summary: %s", policy.consecutiveCrashes(), summaryPath)
+			mng.logger.Error(msg, slog.String("supervisor", supervisorName))
+			event.Send(event.SupervisorCrashLoop(event.WithScreenshot(supervisorName, msg, info.screenshot), policy.consecutiveCrashes(), summaryPath))
+			return
+		}
+
+		mng.logger.Info("Restarting supervisor after crash",
+			slog.String("supervisor", supervisorName),
+			slog.Duration("delay", delay),
+			slog.Int("consecutiveCrashes", policy.consecutiveCrashes()),
+			slog.String("crashSummary", summaryPath),
+		)
+		time.Sleep(delay)
 
 		// Get a list of all available Supervisors
 		supervisorList := mng.AvailableSupervisors()
@@ -327,7 +479,7 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 		gameTitle := "D2R - [" + strconv.FormatInt(int64(pid), 10) + "] - " + supervisorName + " - " + cfg.Realm
 		winproc.SetWindowText.Call(uintptr(hwnd), uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(gameTitle))))
 
-		err := mng.Start(supervisorName, false)
+		err = mng.Start(supervisorName, false)
 		if err != nil {
 			mng.logger.Error("Failed to restart supervisor", slog.String("supervisor", supervisorName), slog.String("Error: ", err.Error()))
 		}
@@ -341,10 +493,11 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 }
 
 func (mng *SupervisorManager) GetSupervisorStats(supervisor string) Stats {
-	if mng.supervisors[supervisor] == nil {
+	s, found := mng.supervisor(supervisor)
+	if !found || s == nil {
 		return Stats{}
 	}
-	return mng.supervisors[supervisor].Stats()
+	return s.Stats()
 }
 
 func (mng *SupervisorManager) rearrangeWindows() {
@@ -365,7 +518,7 @@ func (mng *SupervisorManager) rearrangeWindows() {
 	)
 
 	var column, row int32
-	for _, sp := range mng.supervisors {
+	for _, sp := range mng.runningSupervisors() {
 		// reminder that columns are vertical (they go up and down) and rows are horizontal (they go left and right)
 		if column > maxColumns {
 			column = 0
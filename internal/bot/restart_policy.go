@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/lifecycle"
+)
+
+// restartPolicy limits how often a supervisor is restarted after a client crash, it's kept between restarts and
+// consecutive crashes are reset once a game is created
+type restartPolicy struct {
+	mu          sync.Mutex
+	maxRestarts int
+	window      time.Duration
+	giveUpAfter int
+	backoff     *lifecycle.Backoff
+	restarts    []time.Time
+	crashed     bool
+	now         func() time.Time
+}
+
+func newRestartPolicy(cfg *config.CharacterCfg) *restartPolicy {
+	return &restartPolicy{
+		maxRestarts: cfg.Restart.MaxRestarts,
+		window:      time.Duration(cfg.Restart.WindowMinutes) * time.Minute,
+		giveUpAfter: cfg.Restart.GiveUpAfter,
+		backoff: &lifecycle.Backoff{
+			Base:   time.Duration(cfg.Restart.BackoffBase) * time.Second,
+			Max:    time.Duration(cfg.Restart.BackoffMax) * time.Second,
+			Jitter: 0.2,
+		},
+		now: time.Now,
+	}
+}
+
+// next returns the time to wait before restarting, false if the supervisor is crash looping and should not be restarted
+func (p *restartPolicy) next() (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delay := p.backoff.Next()
+	if p.giveUpAfter > 0 && p.backoff.Failures() > p.giveUpAfter {
+		p.crashed = true
+		return 0, false
+	}
+
+	now := p.now()
+	p.restarts = slices.DeleteFunc(p.restarts, func(t time.Time) bool {
+		return now.Sub(t) > p.window
+	})
+
+	// Too many restarts in the window, wait until the oldest one is out of it
+	if p.maxRestarts > 0 && len(p.restarts) >= p.maxRestarts {
+		delay = max(delay, p.window-now.Sub(p.restarts[0]))
+	}
+	p.restarts = append(p.restarts, now.Add(delay))
+
+	return delay, true
+}
+
+func (p *restartPolicy) consecutiveCrashes() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.backoff.Failures()
+}
+
+func (p *restartPolicy) isCrashed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.crashed
+}
+
+func (p *restartPolicy) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.backoff.Reset()
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+// newTestRestartPolicy uses a fixed clock that can be moved by the tests, and no jitter
+func newTestRestartPolicy(maxRestarts, windowMinutes, giveUpAfter int) (*restartPolicy, *time.Time) {
+	cfg := &config.CharacterCfg{}
+	cfg.Restart.MaxRestarts = maxRestarts
+	cfg.Restart.WindowMinutes = windowMinutes
+	cfg.Restart.BackoffBase = 5
+	cfg.Restart.BackoffMax = 60
+	cfg.Restart.GiveUpAfter = giveUpAfter
+
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := newRestartPolicy(cfg)
+	p.backoff.Jitter = 0
+	p.now = func() time.Time { return clock }
+
+	return p, &clock
+}
+
+func TestRestartPolicyBackoff(t *testing.T) {
+	p, _ := newTestRestartPolicy(0, 15, 0)
+
+	expected := []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}
+	for i, want := range expected {
+		delay, restart := p.next()
+		if !restart || delay != want {
+			t.Errorf("Restart %d: got %s (restart: %v), want %s", i+1, delay, restart, want)
+		}
+	}
+	if p.consecutiveCrashes() != len(expected) {
+		t.Errorf("Expected %d consecutive crashes, got %d", len(expected), p.consecutiveCrashes())
+	}
+
+	// A game was created, next crash starts from the base delay again
+	p.reset()
+	if delay, _ := p.next(); delay != 5*time.Second {
+		t.Errorf("Expected base delay after reset, got %s", delay)
+	}
+}
+
+func TestRestartPolicyWindow(t *testing.T) {
+	p, clock := newTestRestartPolicy(3, 15, 0)
+
+	for i := range 3 {
+		if delay, _ := p.next(); delay > time.Minute {
+			t.Fatalf("Restart %d should not be limited by the window, got %s", i+1, delay)
+		}
+		p.reset()
+	}
+
+	// Fourth restart in the window waits until the first one is out of it, first one was done after 5 seconds
+	if delay, _ := p.next(); delay != 15*time.Minute+5*time.Second {
+		t.Errorf("Expected to wait until the window is over, got %s", delay)
+	}
+	p.reset()
+
+	// Once the window is over, restarts are not limited anymore
+	*clock = clock.Add(time.Hour)
+	if delay, _ := p.next(); delay != 5*time.Second {
+		t.Errorf("Expected base delay once the window is over, got %s", delay)
+	}
+}
+
+func TestRestartPolicyGivesUp(t *testing.T) {
+	p, _ := newTestRestartPolicy(0, 15, 3)
+
+	for i := range 3 {
+		if _, restart := p.next(); !restart {
+			t.Fatalf("Restart %d should be allowed", i+1)
+		}
+		if p.isCrashed() {
+			t.Fatalf("Supervisor should not be crashed after %d crashes", i+1)
+		}
+	}
+
+	if delay, restart := p.next(); restart || delay != 0 {
+		t.Errorf("Expected to give up after 3 consecutive crashes, got %s (restart: %v)", delay, restart)
+	}
+	if !p.isCrashed() {
+		t.Error("Supervisor should be marked as crashed")
+	}
+}
+
+func TestRestartPolicyNeverGivesUp(t *testing.T) {
+	p, _ := newTestRestartPolicy(0, 15, 0)
+
+	for i := range 50 {
+		if _, restart := p.next(); !restart {
+			t.Fatalf("Restart %d should be allowed when giving up is disabled", i+1)
+		}
+	}
+}
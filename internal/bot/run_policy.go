@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	botCtx "github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/health"
+	"github.com/hectorgimenez/koolo/internal/run"
+)
+
+// executeRun executes the run until it finishes or max duration is reached, in that case ErrRunTimeout is returned
+func (b *Bot) executeRun(r run.Run, maxDuration time.Duration) (err error) {
+	if maxDuration > 0 {
+		b.ctx.CurrentGame.RunDeadline = time.Now().Add(maxDuration)
+		defer func() {
+			b.ctx.CurrentGame.RunDeadline = time.Time{}
+		}()
+	}
+
+	// Runs restore item pickup and area correction before finishing, a failed attempt could leave them changed
+	// for the next runs when the game continues
+	defer func() {
+		if err != nil {
+			b.ctx.EnableItemPickup()
+			b.ctx.CurrentGame.AreaCorrection.Enabled = false
+		}
+	}()
+
+	// Run is interrupted from PauseIfNotPriority, any other panic (e.g. bot stopped) keeps going up
+	defer func() {
+		if rec := recover(); rec != nil {
+			if recErr, ok := rec.(error); ok && errors.Is(recErr, botCtx.ErrRunTimeout) {
+				err = fmt.Errorf("%s: %w (%s)", r.Name(), botCtx.ErrRunTimeout, maxDuration)
+				return
+			}
+			panic(rec)
+		}
+	}()
+
+	return r.Run()
+}
+
+func runFinishReason(err error) event.FinishReason {
+	switch {
+	case err == nil:
+		return event.FinishedOK
+	case errors.Is(err, health.ErrChicken):
+		return event.FinishedChicken
+	case errors.Is(err, health.ErrMercChicken):
+		return event.FinishedMercChicken
+	case errors.Is(err, health.ErrDied):
+		return event.FinishedDied
+	case errors.Is(err, botCtx.ErrRunTimeout):
+		return event.FinishedTimeout
+	default:
+		return event.FinishedError
+	}
+}
+
+// onRunError returns what to do after a failed attempt, death and chicken always finish the game
+func onRunError(err error, policy config.RunPolicy, attempt int) config.RunOnError {
+	if errors.Is(err, health.ErrChicken) || errors.Is(err, health.ErrMercChicken) || errors.Is(err, health.ErrDied) {
+		return config.RunOnErrorEndGame
+	}
+
+	if policy.OnError == config.RunOnErrorRetry && attempt > policy.Retries {
+		return config.RunOnErrorSkip
+	}
+
+	return policy.OnError
+}
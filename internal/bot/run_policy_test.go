@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	botCtx "github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/health"
+)
+
+func TestOnRunError(t *testing.T) {
+	runErr := errors.New("path not found")
+	timeout := fmt.Errorf("pit: %w", botCtx.ErrRunTimeout)
+	retry := config.RunPolicy{Retries: 2, OnError: config.RunOnErrorRetry}
+	skip := config.RunPolicy{OnError: config.RunOnErrorSkip}
+
+	tests := []struct {
+		name     string
+		err      error
+		policy   config.RunPolicy
+		attempt  int
+		expected config.RunOnError
+	}{
+		{name: "End game", err: runErr, policy: config.RunPolicy{OnError: config.RunOnErrorEndGame}, attempt: 1, expected: config.RunOnErrorEndGame},
+		{name: "Skip", err: runErr, policy: skip, attempt: 1, expected: config.RunOnErrorSkip},
+		{name: "Skip on timeout", err: timeout, policy: skip, attempt: 1, expected: config.RunOnErrorSkip},
+		{name: "Retry", err: runErr, policy: retry, attempt: 1, expected: config.RunOnErrorRetry},
+		{name: "Last retry", err: timeout, policy: retry, attempt: 2, expected: config.RunOnErrorRetry},
+		{name: "Retries exhausted skip the run", err: runErr, policy: retry, attempt: 3, expected: config.RunOnErrorSkip},
+		{name: "Retry without retries skips the run", err: runErr, policy: config.RunPolicy{OnError: config.RunOnErrorRetry}, attempt: 1, expected: config.RunOnErrorSkip},
+		{name: "Died ends the game", err: health.ErrDied, policy: skip, attempt: 1, expected: config.RunOnErrorEndGame},
+		{name: "Chicken ends the game", err: fmt.Errorf("mephisto: %w", health.ErrChicken), policy: retry, attempt: 1, expected: config.RunOnErrorEndGame},
+		{name: "Merc chicken ends the game", err: health.ErrMercChicken, policy: retry, attempt: 1, expected: config.RunOnErrorEndGame},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := onRunError(tt.err, tt.policy, tt.attempt); got != tt.expected {
+				t.Errorf("onRunError(%v, %+v, %d) = %s, want %s", tt.err, tt.policy, tt.attempt, got, tt.expected)
+			}
+		})
+	}
+}
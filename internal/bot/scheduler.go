@@ -50,6 +50,11 @@ func (s *Scheduler) checkSchedules() {
 			continue
 		}
 
+		// Supervisors that gave up after crashing in a loop are only started again manually
+		if p := s.manager.restartPolicy(supervisorName); p != nil && p.isCrashed() {
+			continue
+		}
+
 		for _, day := range cfg.Scheduler.Days {
 			if day.DayOfWeek != currentDay {
 				continue
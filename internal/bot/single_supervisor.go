@@ -14,12 +14,17 @@ import (
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/health"
+	"github.com/hectorgimenez/koolo/internal/lifecycle"
 	"github.com/hectorgimenez/koolo/internal/run"
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
 
 type SinglePlayerSupervisor struct {
 	*baseSupervisor
+	joinedGames      *game.GameBlacklist
+	joinedPublicGame bool
+	// onlineBackoff is kept between games, so consecutive bnet failures are counted until we get in game
+	onlineBackoff *lifecycle.Backoff
 }
 
 func (s *SinglePlayerSupervisor) GetData() *game.Data {
@@ -38,6 +43,15 @@ func NewSinglePlayerSupervisor(name string, bot *Bot, statsHandler *StatsHandler
 
 	return &SinglePlayerSupervisor{
 		baseSupervisor: bs,
+		joinedGames: game.NewGameBlacklist(func() time.Duration {
+			return time.Duration(bot.ctx.CharacterCfg.Game.JoinGames.BlacklistMinutes) * time.Minute
+		}),
+		onlineBackoff: &lifecycle.Backoff{
+			Base:        time.Duration(bot.ctx.CharacterCfg.BattleNet.BackoffBase) * time.Second,
+			Max:         time.Duration(bot.ctx.CharacterCfg.BattleNet.BackoffMax) * time.Second,
+			MaxFailures: bot.ctx.CharacterCfg.BattleNet.MaxFailures,
+			Jitter:      0.2,
+		},
 	}, nil
 }
 
@@ -67,19 +81,13 @@ func (s *SinglePlayerSupervisor) Start() error {
 			// By this point, we should be in the character selection screen.
 			if !s.bot.ctx.Manager.InGame() {
 				// Create the game
-				if err = s.HandleOutOfGameFlow(); err != nil {
-					// Ignore loading screen errors or unhandled errors (for now) and try again
-					if err.Error() == "loading screen" || err.Error() == "" {
-						utils.Sleep(100)
-						continue
-					}
-
+				if err = s.HandleOutOfGameFlow(ctx); err != nil {
 					s.bot.ctx.Logger.Error(fmt.Sprintf("Error creating new game: %s", err.Error()))
 					continue
 				}
 			}
 
-			runs := run.BuildRuns(s.bot.ctx.CharacterCfg)
+			runs := s.buildRuns()
 			gameStart := time.Now()
 			if config.Characters[s.name].Game.RandomizeRuns {
 				rand.Shuffle(len(runs), func(i, j int) { runs[i], runs[j] = runs[j], runs[i] })
@@ -132,7 +140,7 @@ func (s *SinglePlayerSupervisor) Start() error {
 				event.Send(event.GameFinished(event.Text(s.name, "Game finished successfully"), gameFinishReason))
 			}
 
-			if exitErr := s.bot.ctx.Manager.ExitGame(); exitErr != nil {
+			if exitErr := s.newLifecycle().Exit(); exitErr != nil {
 				errMsg := fmt.Sprintf("Error exiting game %s", exitErr.Error())
 				event.Send(event.GameFinished(event.WithScreenshot(s.name, errMsg, s.bot.ctx.GameReader.Screenshot()), event.FinishedError))
 				return errors.New(errMsg)
@@ -141,149 +149,90 @@ func (s *SinglePlayerSupervisor) Start() error {
 	}
 }
 
-// This function is responsible for handling all interactions with joining/creating games
-func (s *SinglePlayerSupervisor) HandleOutOfGameFlow() error {
-	// Refresh the data
-	s.bot.ctx.RefreshGameData()
-
-	// Catch-all
-	if s.bot.ctx.CharacterCfg.AuthMethod != "None" && s.bot.ctx.GameReader.IsInCharacterSelectionScreen() && !s.bot.ctx.GameReader.IsOnline() {
-
-		// Try and click the online tab to re-connect to bnet
-		s.bot.ctx.HID.Click(game.LeftButton, 1090, 32) // click the online button
+// HandleOutOfGameFlow is responsible for handling all interactions with joining/creating games, it returns once we
+// are in game
+func (s *SinglePlayerSupervisor) HandleOutOfGameFlow(ctx context.Context) error {
+	s.joinedPublicGame = false
 
-		// Wait a bit
-		utils.Sleep(4000)
-
-		// Check if we're online again, if not, kill the client
-		if !s.bot.ctx.GameReader.IsOnline() {
-
-			// Kill the client so the crash detector will restart it
-			if err := s.KillClient(); err != nil {
-				return err
-			}
+	return s.newLifecycle().Run(ctx)
+}
 
-			return fmt.Errorf("we've lost connection to bnet or client glitched. The d2r process will be killed")
-		}
+// newLifecycle is created every time, so config changes (e.g. joining instead of creating games) are applied
+func (s *SinglePlayerSupervisor) newLifecycle() *lifecycle.Machine {
+	mode := lifecycle.ModeCreateGame
+	switch {
+	// Followers need a lobby game to join the leader
+	case s.bot.ctx.CharacterCfg.Companion.Enabled && s.bot.ctx.CharacterCfg.Companion.Leader:
+		mode = lifecycle.ModeCreateLobbyGame
+	case s.bot.ctx.CharacterCfg.Game.JoinGames.Enabled:
+		mode = lifecycle.ModeJoinLobbyGame
+	case s.bot.ctx.CharacterCfg.Game.CreateLobbyGames:
+		mode = lifecycle.ModeCreateLobbyGame
 	}
 
-	// We're either in the in the Lobby or Character selection screen. Let's check
-	if s.bot.ctx.GameReader.IsInCharacterSelectionScreen() {
-		// TODO: Add Joining Games
-
-		if s.bot.ctx.CharacterCfg.Game.CreateLobbyGames {
-			retryCount := 0
-			for !s.bot.ctx.GameReader.IsInLobby() {
-
-				// Prevent an infinite loop
-				if retryCount >= 5 && !s.bot.ctx.GameReader.IsInLobby() {
-					return fmt.Errorf("failed to enter bnet lobby after 5 retries")
-				}
-
-				// Try to enter bnet lobby
-				s.bot.ctx.HID.Click(game.LeftButton, 744, 650)
-				utils.Sleep(1000)
-			}
-
-			if _, err := s.bot.ctx.Manager.CreateOnlineGame(s.bot.ctx.CharacterCfg.Game.PublicGameCounter); err != nil {
-				s.bot.ctx.CharacterCfg.Game.PublicGameCounter++
-				return fmt.Errorf("failed to create an online game")
-
-			} else {
-				// We created the game successfully!
-				s.bot.ctx.CharacterCfg.Game.PublicGameCounter++
-				return nil
-			}
-		} else {
-			// TODO: Add logic to check if we're on the online or offline tab and handle it accordingly.
-			if !s.bot.ctx.GameReader.IsOnline() && s.bot.ctx.CharacterCfg.AuthMethod != "None" {
-
-				// Try and click the online tab to re-connect to bnet
-				s.bot.ctx.HID.Click(game.LeftButton, 1090, 32) // click the online button
-
-				// Wait a bit
-				utils.Sleep(4000)
-
-				// Check again
-				if !s.bot.ctx.GameReader.IsOnline() {
-					// We failed to re-connect. Kill the client so it will get re-started automatically.
-					if err := s.KillClient(); err != nil {
-						return err
-					}
-					return fmt.Errorf("lost connection to bnet, killing client")
-				}
-			}
-
-			// Create the game
-			if err := s.bot.ctx.Manager.NewGame(); err != nil {
-				return fmt.Errorf("failed to create game")
-			}
+	flow := &clientFlow{supervisor: s}
+	m := lifecycle.New(flow, flow, mode, s.bot.ctx.CharacterCfg.AuthMethod != "None")
+	m.Backoff = s.onlineBackoff
+	m.OnTransition = func(t lifecycle.Transition) {
+		s.bot.ctx.Logger.Debug("Out of game state changed",
+			slog.String("from", string(t.From)),
+			slog.String("to", string(t.To)),
+			slog.String("reason", t.Reason),
+		)
+	}
 
-			return nil
+	m.OnOnlineFailure = func(f lifecycle.OnlineFailure) {
+		msg := fmt.Sprintf("Bnet error (%s): %s. Failure %d/%d, retrying in %s",
+			f.State, f.Message, f.Failures, s.onlineBackoff.MaxFailures, f.Backoff.Round(time.Second))
+		if s.onlineBackoff.MaxFailures == 0 {
+			msg = fmt.Sprintf("Bnet error (%s): %s. Failure %d, retrying in %s", f.State, f.Message, f.Failures, f.Backoff.Round(time.Second))
 		}
-	} else if s.bot.ctx.GameReader.IsInLobby() {
-		// TODO: Add Joining Games
-
-		// Check if we are suppose to create lobby games and enter lobby.
-		if s.bot.ctx.CharacterCfg.Game.CreateLobbyGames {
-
-			retryCount := 0
-			for !s.bot.ctx.GameReader.IsInLobby() {
-
-				// Prevent an infinite loop
-				if retryCount >= 5 && !s.bot.ctx.GameReader.IsInLobby() {
-					return fmt.Errorf("failed to enter bnet lobby after 5 retries")
-				}
-
-				// Try to enter bnet lobby
-				s.bot.ctx.HID.Click(game.LeftButton, 744, 650)
-				utils.Sleep(1000)
-			}
-
-			if _, err := s.bot.ctx.Manager.CreateOnlineGame(s.bot.ctx.CharacterCfg.Game.PublicGameCounter); err != nil {
-				s.bot.ctx.CharacterCfg.Game.PublicGameCounter++
-				return fmt.Errorf("failed to create an online game")
+		if f.RestartClient {
+			msg = fmt.Sprintf("Bnet error (%s): %s. %d consecutive failures, restarting the game client", f.State, f.Message, f.Failures)
+		}
+		s.bot.ctx.Logger.Warn(msg)
+		event.Send(event.OnlineFailure(event.Text(s.name, msg), f.Message, f.Failures, f.Backoff, f.RestartClient))
+	}
 
-			} else {
-				// We created the game successfully!
-				s.bot.ctx.CharacterCfg.Game.PublicGameCounter++
-				return nil
-			}
-		} else {
-			// Press escape to exit the lobby
-			s.bot.ctx.HID.PressKey(0x1B) // ESC - to avoid importing win here as well
-			utils.Sleep(1000)
+	return m
+}
 
-			for range 5 {
-				if s.bot.ctx.GameReader.IsInCharacterSelectionScreen() && s.bot.ctx.GameReader.IsOnline() {
-					break
-				}
+// joinPublicGame joins the first game from the list matching the join filters
+func (s *SinglePlayerSupervisor) joinPublicGame() error {
+	games := s.bot.ctx.Manager.LobbyGames()
+	g, found, err := game.FindGameToJoin(games, s.bot.ctx.CharacterCfg, s.joinedGames)
+	if err != nil {
+		return err
+	}
+	if !found {
+		s.bot.ctx.Logger.Debug("No games matching the join filters, waiting for the list to be refreshed", slog.Int("games", len(games)))
+		utils.Sleep(5000)
+		return fmt.Errorf("no games to join")
+	}
 
-				if s.bot.ctx.GameReader.IsInLobby() {
-					// Mission failed
-					s.bot.ctx.HID.PressKey(0x1B) // ESC - to avoid importing win here as well
-					utils.Sleep(1000)
-				}
-			}
+	// Blacklist it even if we fail to join, the game could be full or already finished
+	s.joinedGames.Add(g.Name)
+	s.bot.ctx.Logger.Info("Joining public game",
+		slog.String("game", g.Name),
+		slog.Int("players", g.Players),
+		slog.String("difficulty", string(g.Difficulty)),
+	)
+	if err = s.bot.ctx.Manager.JoinOnlineGame(g.Name, ""); err != nil {
+		return fmt.Errorf("failed to join game %s: %w", g.Name, err)
+	}
+	s.joinedPublicGame = true
 
-			if !s.bot.ctx.GameReader.IsInCharacterSelectionScreen() {
-				return fmt.Errorf("failed to leave lobby or an unknown case occurred")
-			}
+	return nil
+}
 
-			// Create the game
-			if err := s.bot.ctx.Manager.NewGame(); err != nil {
-				return fmt.Errorf("failed to create game")
-			}
-		}
-	} else if s.bot.ctx.Data.OpenMenus.LoadingScreen {
-		// We're in a loading screen, wait a bit
-		utils.Sleep(250)
-		return fmt.Errorf("loading screen")
-	} else {
-		return fmt.Errorf("")
+// buildRuns returns the configured runs, public games joined from the lobby can use their own runs
+func (s *SinglePlayerSupervisor) buildRuns() []run.Run {
+	if !s.joinedPublicGame || len(s.bot.ctx.CharacterCfg.Game.JoinGames.Runs) == 0 {
+		return run.BuildRuns(s.bot.ctx.CharacterCfg)
 	}
 
-	// TODO: Maybe expand this with functionality to create new characters if the currently configured char isn't found? :)
+	cfg := *s.bot.ctx.CharacterCfg
+	cfg.Game.Runs = cfg.Game.JoinGames.Runs
 
-	return nil
+	return run.BuildRuns(&cfg)
 }
@@ -60,6 +60,7 @@ func (h *StatsHandler) Handle(_ context.Context, e event.Event) error {
 		if len(h.stats.Games) > 0 {
 			h.stats.Games[len(h.stats.Games)-1].Runs = append(h.stats.Games[len(h.stats.Games)-1].Runs, RunStats{
 				Name:      evt.RunName,
+				Attempt:   evt.Attempt,
 				StartedAt: evt.OccurredAt(),
 			})
 		}
@@ -81,6 +82,32 @@ func (h *StatsHandler) Handle(_ context.Context, e event.Event) error {
 	case event.ItemStashedEvent:
 		h.stats.Drops = append(h.stats.Drops, evt.Item)
 
+	case event.ItemGambledEvent:
+		h.stats.Gambling.ItemsBought++
+		h.stats.Gambling.GoldSpent += evt.Price
+		if h.stats.Gambling.Qualities == nil {
+			h.stats.Gambling.Qualities = make(map[string]int)
+		}
+		h.stats.Gambling.Qualities[evt.Item.Quality.ToString()]++
+		if evt.Kept {
+			h.stats.Gambling.ItemsKept++
+		}
+
+	case event.GamblingFinishedEvent:
+		h.stats.Gambling.Sessions++
+		h.stats.Gambling.NetGoldSpent += evt.Spent
+
+	case event.CubeRecipeTransmutedEvent:
+		if h.stats.Crafting == nil {
+			h.stats.Crafting = make(map[string]CraftingStats)
+		}
+		recipeStats := h.stats.Crafting[evt.Recipe]
+		recipeStats.Transmutes++
+		recipeStats.InputsConsumed += evt.InputsConsumed
+		recipeStats.Outputs += len(evt.Outputs)
+		recipeStats.Keepers += evt.Keepers
+		h.stats.Crafting[evt.Recipe] = recipeStats
+
 	case event.UsedPotionEvent:
 		if len(h.stats.Games) > 0 && len(h.stats.Games[len(h.stats.Games)-1].Runs) > 0 {
 			lastRun := &h.stats.Games[len(h.stats.Games)-1].Runs[len(h.stats.Games[len(h.stats.Games)-1].Runs)-1]
@@ -101,6 +128,24 @@ type Stats struct {
 	Details          string
 	Drops            []data.Drop
 	Games            []GameStats
+	Gambling         GamblingStats
+	Crafting         map[string]CraftingStats
+}
+
+type GamblingStats struct {
+	Sessions     int
+	ItemsBought  int
+	ItemsKept    int
+	GoldSpent    int // Gold paid for the gambled items
+	NetGoldSpent int // Gold spent discounting the gold from the sold items
+	Qualities    map[string]int
+}
+
+type CraftingStats struct {
+	Transmutes     int
+	InputsConsumed int
+	Outputs        int
+	Keepers        int // Outputs matching the NIP rules, the rest are sold or dropped
 }
 
 type GameStats struct {
@@ -110,8 +155,10 @@ type GameStats struct {
 	Runs       []RunStats
 }
 
+// RunStats is recorded for every attempt, retried runs have one entry per attempt
 type RunStats struct {
 	Name        string
+	Attempt     int
 	Reason      event.FinishReason
 	StartedAt   time.Time
 	Items       []data.Item
@@ -132,7 +179,20 @@ func (s Stats) TotalChickens() int {
 }
 
 func (s Stats) TotalErrors() int {
-	return s.totalRunsByReason(event.FinishedError)
+	return s.totalRunsByReason(event.FinishedError) + s.totalRunsByReason(event.FinishedTimeout)
+}
+
+func (s Stats) TotalRetries() int {
+	total := 0
+	for _, g := range s.Games {
+		for _, r := range g.Runs {
+			if r.Attempt > 1 {
+				total++
+			}
+		}
+	}
+
+	return total
 }
 
 func (s Stats) totalRunsByReason(reason event.FinishReason) int {
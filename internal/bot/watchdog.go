@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/action"
+	"github.com/hectorgimenez/koolo/internal/action/step"
+	botCtx "github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/pather"
+)
+
+// Moving less than this from the last known position is not considered progress, this way we detect door loops and
+// characters walking against a wall
+const watchdogMinDistance = 10
+
+var ErrStuck = errors.New("character is stuck")
+
+// Recoveries are applied in order, one per stall, any progress resets them. Last one is leaving the game.
+var watchdogRecoveries = []struct {
+	name string
+	fn   func() error
+}{
+	{name: "close menus", fn: step.CloseAllMenus},
+	{name: "random movement", fn: func() error {
+		botCtx.Get().PathFinder.RandomMovement()
+		return nil
+	}},
+	{name: "return to town", fn: action.ReturnTown},
+}
+
+// watchdogRecovery is executed by the high priority loop, this way the normal priority loop is paused while recovering
+// and only one goroutine is driving the character
+type watchdogRecovery struct {
+	fn   func() error
+	done chan error
+}
+
+type progressSnapshot struct {
+	area        area.ID
+	position    data.Position
+	hp          int
+	lastAction  string
+	enemiesLife int
+}
+
+func (b *Bot) progressSnapshot() progressSnapshot {
+	enemiesLife := 0
+	for _, m := range b.ctx.Data.Monsters.Enemies() {
+		enemiesLife += m.Stats[stat.Life]
+	}
+
+	return progressSnapshot{
+		area:        b.ctx.Data.PlayerUnit.Area,
+		position:    b.ctx.Data.PlayerUnit.Position,
+		hp:          b.ctx.Data.PlayerUnit.HPPercent(),
+		lastAction:  b.ctx.ContextDebug[botCtx.PriorityNormal].LastAction,
+		enemiesLife: enemiesLife,
+	}
+}
+
+// progressed returns true if something happened since the last snapshot, fighting, moving or executing other actions
+func (s progressSnapshot) progressed(last progressSnapshot) bool {
+	return s.area != last.area ||
+		pather.DistanceFromPoint(s.position, last.position) > watchdogMinDistance ||
+		s.hp != last.hp ||
+		s.lastAction != last.lastAction ||
+		s.enemiesLife < last.enemiesLife
+}
+
+// watchdog detects when the bot is not making any progress and tries to recover it, escalating on every new stall.
+// Recoveries are sent to the high priority loop. It returns ErrStuck when nothing worked, so the game is finished
+// instead of wasting the whole max game length.
+func (b *Bot) watchdog(ctx context.Context, recoveries chan<- watchdogRecovery) error {
+	// It only reads the game data, interacting with the game is done by the high priority loop
+	b.ctx.AttachRoutine(botCtx.PriorityBackground)
+
+	stallTimeout := time.Duration(b.ctx.CharacterCfg.Watchdog.StallSeconds) * time.Second
+	last := b.progressSnapshot()
+	lastProgressAt := time.Now()
+	recovery := 0
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// Paused time is not a stall
+		if b.ctx.ExecutionPriority == botCtx.PriorityPause {
+			lastProgressAt = time.Now()
+			continue
+		}
+
+		current := b.progressSnapshot()
+		if current.progressed(last) {
+			last = current
+			lastProgressAt = time.Now()
+			recovery = 0
+			continue
+		}
+
+		if time.Since(lastProgressAt) < stallTimeout {
+			continue
+		}
+
+		recoveryName := "exit game"
+		if recovery < len(watchdogRecoveries) {
+			recoveryName = watchdogRecoveries[recovery].name
+		}
+
+		msg := fmt.Sprintf("Character stuck for %0.fs (last action: %s, last step: %s), trying to recover: %s",
+			time.Since(lastProgressAt).Seconds(),
+			b.ctx.ContextDebug[botCtx.PriorityNormal].LastAction,
+			b.ctx.ContextDebug[botCtx.PriorityNormal].LastStep,
+			recoveryName,
+		)
+		b.ctx.Logger.Warn(msg, slog.Any("area", current.area), slog.Bool("menuOpen", b.ctx.Data.OpenMenus.IsMenuOpen()))
+		event.Send(event.StuckDetected(event.WithScreenshot(b.ctx.Name, msg, b.ctx.GameReader.Screenshot()), recoveryName, time.Since(lastProgressAt)))
+
+		if recovery >= len(watchdogRecoveries) {
+			return ErrStuck
+		}
+
+		r := watchdogRecovery{fn: watchdogRecoveries[recovery].fn, done: make(chan error, 1)}
+		select {
+		case <-ctx.Done():
+			return nil
+		case recoveries <- r:
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-r.done:
+			if err != nil {
+				b.ctx.Logger.Warn("Stuck recovery failed", slog.String("recovery", recoveryName), slog.Any("error", err))
+			}
+		}
+
+		recovery++
+		last = b.progressSnapshot()
+		lastProgressAt = time.Now()
+	}
+}
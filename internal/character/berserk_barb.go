@@ -209,6 +209,11 @@ func (s *Berserker) SwapToSlot(slot int) {
 	}
 }
 
+// DamageResists berserk converts all the damage to magic
+func (s *Berserker) DamageResists() []stat.Resist {
+	return []stat.Resist{stat.MagicImmune}
+}
+
 func (s *Berserker) BuffSkills() []skill.ID {
 
 	skillsList := make([]skill.ID, 0)
@@ -333,11 +338,16 @@ func (s *Berserker) killAllCouncilMembers() error {
 		}
 
 		err := s.KillMonsterSequence(func(d game.Data) (data.UnitID, bool) {
+			var councilMembers []data.Monster
 			for _, m := range d.Monsters.Enemies() {
 				if (m.Name == npc.CouncilMember || m.Name == npc.CouncilMember2 || m.Name == npc.CouncilMember3) && m.Stats[stat.Life] > 0 {
-					return m.UnitID, true
+					councilMembers = append(councilMembers, m)
 				}
 			}
+
+			if m, found := d.BestTarget(councilMembers, s.IsImmuneToCharacter); found {
+				return m.UnitID, true
+			}
 			return 0, false
 		}, nil)
 
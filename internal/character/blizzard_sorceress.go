@@ -106,7 +106,7 @@ func (s BlizzardSorceress) KillMonsterSequence(
 			step.PrimaryAttack(id, 2, true, lsOpts)
 		}
 
-		step.SecondaryAttack(skill.Blizzard, id, 1, blizzOpts)
+		step.SecondaryAttack(skill.Blizzard, id, 1, blizzOpts, step.Kite())
 
 		completedAttackLoops++
 		previousUnitID = int(id)
@@ -153,6 +153,11 @@ func (s BlizzardSorceress) killMonsterByName(id npc.ID, monsterType data.Monster
 	return nil
 }
 
+// DamageResists cold immunes can not be damaged by blizzard
+func (s BlizzardSorceress) DamageResists() []stat.Resist {
+	return []stat.Resist{stat.ColdImmune}
+}
+
 func (s BlizzardSorceress) BuffSkills() []skill.ID {
 	skillsList := make([]skill.ID, 0)
 	if _, found := s.Data.KeyBindings.KeyBindingForSkill(skill.EnergyShield); found {
@@ -194,18 +199,14 @@ func (s BlizzardSorceress) KillCouncil() error {
 	return s.KillMonsterSequence(func(d game.Data) (data.UnitID, bool) {
 		// Exclude monsters that are not council members
 		var councilMembers []data.Monster
-		var coldImmunes []data.Monster
 		for _, m := range d.Monsters.Enemies() {
 			if m.Name == npc.CouncilMember || m.Name == npc.CouncilMember2 || m.Name == npc.CouncilMember3 {
-				if m.IsImmune(stat.ColdImmune) {
-					coldImmunes = append(coldImmunes, m)
-				} else {
-					councilMembers = append(councilMembers, m)
-				}
+				councilMembers = append(councilMembers, m)
 			}
 		}
 
-		councilMembers = append(councilMembers, coldImmunes...)
+		// Immune council members will be the last ones
+		d.SortByTargetPriority(councilMembers, s.IsImmuneToCharacter)
 
 		for _, m := range councilMembers {
 			return m.UnitID, true
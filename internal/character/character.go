@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/koolo/internal/context"
 )
@@ -16,19 +17,45 @@ func BuildCharacter(ctx *context.Context) (context.Character, error) {
 	}
 
 	if len(ctx.CharacterCfg.Game.Runs) > 0 && ctx.CharacterCfg.Game.Runs[0] == "leveling" {
-		switch strings.ToLower(ctx.CharacterCfg.Character.Class) {
-		case "sorceress_leveling_lightning":
-			return SorceressLevelingLightning{BaseCharacter: bc}, nil
-		case "sorceress_leveling":
-			return SorceressLeveling{BaseCharacter: bc}, nil
-		case "paladin":
-			return PaladinLeveling{BaseCharacter: bc}, nil
+		// Build templates only define stats, skills and bindings, any class can be used as combat routine
+		if build := ctx.CharacterCfg.Runtime.LevelingBuild; build != nil {
+			combat, err := levelingCharacter(bc, build.Class)
+			if err != nil {
+				combat, err = character(bc, build.Class)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("leveling build %s: %w", build.Name, err)
+			}
+
+			levelingChar := LevelingBuildCharacter{Character: combat, BaseCharacter: bc, build: build}
+			if berserker, ok := combat.(*Berserker); ok {
+				return berserkerLevelingBuild{LevelingBuildCharacter: levelingChar, berserker: berserker}, nil
+			}
+
+			return levelingChar, nil
 		}
 
-		return nil, fmt.Errorf("leveling only available for sorceress and paladin")
+		return levelingCharacter(bc, ctx.CharacterCfg.Character.Class)
+	}
+
+	return character(bc, ctx.CharacterCfg.Character.Class)
+}
+
+func levelingCharacter(bc BaseCharacter, class string) (context.Character, error) {
+	switch strings.ToLower(class) {
+	case "sorceress_leveling_lightning":
+		return SorceressLevelingLightning{BaseCharacter: bc}, nil
+	case "sorceress_leveling":
+		return SorceressLeveling{BaseCharacter: bc}, nil
+	case "paladin":
+		return PaladinLeveling{BaseCharacter: bc}, nil
 	}
 
-	switch strings.ToLower(ctx.CharacterCfg.Character.Class) {
+	return nil, fmt.Errorf("leveling only available for sorceress and paladin")
+}
+
+func character(bc BaseCharacter, class string) (context.Character, error) {
+	switch strings.ToLower(class) {
 	case "sorceress":
 		return BlizzardSorceress{BaseCharacter: bc}, nil
 	case "fireballsorc":
@@ -55,7 +82,7 @@ func BuildCharacter(ctx *context.Context) (context.Character, error) {
 		return &Berserker{BaseCharacter: bc}, nil // Return a pointer to Berserker
 	}
 
-	return nil, fmt.Errorf("class %s not implemented", ctx.CharacterCfg.Character.Class)
+	return nil, fmt.Errorf("class %s not implemented", class)
 }
 
 type BaseCharacter struct {
@@ -67,6 +94,8 @@ func (bc BaseCharacter) preBattleChecks(id data.UnitID, skipOnImmunities []stat.
 	if !found {
 		return false
 	}
+
+	// Immunities configured for the run are always respected
 	for _, i := range skipOnImmunities {
 		if monster.IsImmune(i) {
 			bc.Logger.Info("Monster is immune! skipping", slog.String("immuneTo", string(i)))
@@ -74,5 +103,35 @@ func (bc BaseCharacter) preBattleChecks(id data.UnitID, skipOnImmunities []stat.
 		}
 	}
 
+	// Characters with multiple elements also skip the monster if none of their skills can damage it
+	if mec, ok := bc.Char.(context.MultiElementCharacter); ok && len(mec.AttackSkills()) > 0 {
+		if _, found := selectAttackSkill(monster, mec.AttackSkills()); !found {
+			bc.Logger.Info("Monster is immune to all our attack skills! skipping")
+			return false
+		}
+	}
+
 	return true
 }
+
+// selectAttackSkill returns the first skill able to damage the monster, respecting the preference order
+func selectAttackSkill(monster data.Monster, skills []context.AttackSkill) (context.AttackSkill, bool) {
+	for _, s := range skills {
+		if s.CanDamage(monster) {
+			return s, true
+		}
+	}
+
+	return context.AttackSkill{}, false
+}
+
+// canDamageWith returns true if the given skill is declared and it's able to damage the monster
+func canDamageWith(monster data.Monster, skills []context.AttackSkill, id skill.ID) bool {
+	for _, s := range skills {
+		if s.Skill == id {
+			return s.CanDamage(monster)
+		}
+	}
+
+	return false
+}
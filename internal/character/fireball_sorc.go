@@ -124,6 +124,11 @@ func (f FireballSorceress) killMonsterByName(id npc.ID, monsterType data.Monster
 	}, skipOnImmunities)
 }
 
+// DamageResists fire immunes can not be damaged by fireball
+func (f FireballSorceress) DamageResists() []stat.Resist {
+	return []stat.Resist{stat.FireImmune}
+}
+
 func (f FireballSorceress) BuffSkills() []skill.ID {
 	skillsList := make([]skill.ID, 0)
 	if _, found := f.Data.KeyBindings.KeyBindingForSkill(skill.EnergyShield); found {
@@ -166,20 +171,16 @@ func (f FireballSorceress) KillCouncil() error {
 	return f.KillMonsterSequence(func(d game.Data) (data.UnitID, bool) {
 		// Exclude monsters that are not council members
 		var councilMembers []data.Monster
-		var fireImmunes []data.Monster
 		for _, m := range d.Monsters.Enemies() {
 
 			if m.Name == npc.CouncilMember || m.Name == npc.CouncilMember2 || m.Name == npc.CouncilMember3 {
-				if m.IsImmune(stat.FireImmune) {
-					fireImmunes = append(fireImmunes, m)
-				} else {
-					councilMembers = append(councilMembers, m)
-				}
+				councilMembers = append(councilMembers, m)
 			}
 
 		}
 
-		councilMembers = append(councilMembers, fireImmunes...)
+		// Immune council members will be the last ones
+		d.SortByTargetPriority(councilMembers, f.IsImmuneToCharacter)
 
 		for _, m := range councilMembers {
 			return m.UnitID, true
@@ -276,6 +276,11 @@ func (f Foh) KillBossSequence(monsterSelector func(d game.Data) (data.UnitID, bo
 	}
 }
 
+// DamageResists fist of the heavens is lightning and holy bolt magic damage
+func (f Foh) DamageResists() []stat.Resist {
+	return []stat.Resist{stat.LightImmune, stat.MagicImmune}
+}
+
 func (f Foh) BuffSkills() []skill.ID {
 	if _, found := f.Data.KeyBindings.KeyBindingForSkill(skill.HolyShield); found {
 		return []skill.ID{skill.HolyShield}
@@ -341,11 +346,16 @@ func (f Foh) killAllCouncilMembers() error {
 		}
 
 		err := f.KillMonsterSequence(func(d game.Data) (data.UnitID, bool) {
+			var councilMembers []data.Monster
 			for _, m := range d.Monsters.Enemies() {
 				if (m.Name == npc.CouncilMember || m.Name == npc.CouncilMember2 || m.Name == npc.CouncilMember3) && m.Stats[stat.Life] > 0 {
-					return m.UnitID, true
+					councilMembers = append(councilMembers, m)
 				}
 			}
+
+			if m, found := d.BestTarget(councilMembers, f.IsImmuneToCharacter); found {
+				return m.UnitID, true
+			}
 			return 0, false
 		}, nil)
 
@@ -3,7 +3,6 @@ package character
 import (
 	"fmt"
 	"log/slog"
-	"sort"
 	"time"
 
 	"github.com/hectorgimenez/koolo/internal/action/step"
@@ -112,6 +111,11 @@ func (s Hammerdin) killMonsterByName(id npc.ID, monsterType data.MonsterType, _
 	}, nil)
 }
 
+// DamageResists magic immunes can not be damaged by blessed hammer
+func (s Hammerdin) DamageResists() []stat.Resist {
+	return []stat.Resist{stat.MagicImmune}
+}
+
 func (s Hammerdin) BuffSkills() []skill.ID {
 	if _, found := s.Data.KeyBindings.KeyBindingForSkill(skill.HolyShield); found {
 		return []skill.ID{skill.HolyShield}
@@ -148,13 +152,8 @@ func (s Hammerdin) KillCouncil() error {
 			}
 		}
 
-		// Order council members by distance
-		sort.Slice(councilMembers, func(i, j int) bool {
-			distanceI := s.PathFinder.DistanceFromMe(councilMembers[i].Position)
-			distanceJ := s.PathFinder.DistanceFromMe(councilMembers[j].Position)
-
-			return distanceI < distanceJ
-		})
+		// Order council members by target priority
+		d.SortByTargetPriority(councilMembers, s.IsImmuneToCharacter)
 
 		for _, m := range councilMembers {
 			return m.UnitID, true
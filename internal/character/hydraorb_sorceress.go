@@ -11,6 +11,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/d2go/pkg/data/state"
 	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/game"
 )
 
@@ -95,17 +96,30 @@ func (s HydraOrbSorceress) KillMonsterSequence(
 		//	}
 		//}
 
-		if s.Data.PlayerUnit.States.HasState(state.Cooldown) {
+		attackSkill, found := selectAttackSkill(monster, s.AttackSkills())
+		if !found {
+			return nil
+		}
+
+		// Hydra is used while Frozen Orb is on cooldown, or alone if the monster is immune to cold
+		if attackSkill.Skill == skill.FrozenOrb && s.Data.PlayerUnit.States.HasState(state.Cooldown) {
 			step.SecondaryAttack(skill.Hydra, id, 1, opts)
 		}
 
-		step.SecondaryAttack(skill.FrozenOrb, id, 1, opts)
+		step.SecondaryAttack(attackSkill.Skill, id, 1, opts)
 
 		completedAttackLoops++
 		previousUnitID = int(id)
 	}
 }
 
+func (s HydraOrbSorceress) AttackSkills() []context.AttackSkill {
+	return []context.AttackSkill{
+		{Skill: skill.FrozenOrb, Resists: []stat.Resist{stat.ColdImmune}},
+		{Skill: skill.Hydra, Resists: []stat.Resist{stat.FireImmune}},
+	}
+}
+
 func (s HydraOrbSorceress) killMonster(npc npc.ID, t data.MonsterType) error {
 	return s.KillMonsterSequence(func(d game.Data) (data.UnitID, bool) {
 		m, found := d.Monsters.FindOne(npc, t)
@@ -178,6 +192,8 @@ func (s HydraOrbSorceress) KillCouncil() error {
 			}
 		}
 
+		// Order council members by target priority, keeping the ones immune to both elements as the last ones
+		d.SortByTargetPriority(councilMembers, s.IsImmuneToCharacter)
 		councilMembers = append(councilMembers, veryImmunes...)
 
 		for _, m := range councilMembers {
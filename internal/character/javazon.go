@@ -3,7 +3,6 @@ package character
 import (
 	"fmt"
 	"log/slog"
-	"sort"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
@@ -87,7 +86,7 @@ func (s Javazon) KillMonsterSequence(
 		}
 
 		if closeMonsters >= 3 {
-			step.SecondaryAttack(skill.LightningFury, id, numOfAttacks, step.Distance(minJavazonDistance, maxJavazonDistance))
+			step.SecondaryAttack(skill.LightningFury, id, numOfAttacks, step.Distance(minJavazonDistance, maxJavazonDistance), step.Kite())
 		} else {
 			step.PrimaryAttack(id, numOfAttacks, false, step.Distance(1, 1))
 		}
@@ -159,6 +158,11 @@ func (s Javazon) PreCTABuffSkills() []skill.ID {
 	}
 }
 
+// DamageResists lightning immunes can not be damaged by lightning fury
+func (s Javazon) DamageResists() []stat.Resist {
+	return []stat.Resist{stat.LightImmune}
+}
+
 func (s Javazon) BuffSkills() []skill.ID {
 	return []skill.ID{}
 }
@@ -189,13 +193,8 @@ func (s Javazon) KillCouncil() error {
 			}
 		}
 
-		// Order council members by distance
-		sort.Slice(councilMembers, func(i, j int) bool {
-			distanceI := s.PathFinder.DistanceFromMe(councilMembers[i].Position)
-			distanceJ := s.PathFinder.DistanceFromMe(councilMembers[j].Position)
-
-			return distanceI < distanceJ
-		})
+		// Order council members by target priority
+		d.SortByTargetPriority(councilMembers, s.IsImmuneToCharacter)
 
 		for _, m := range councilMembers {
 			return m.UnitID, true
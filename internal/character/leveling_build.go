@@ -0,0 +1,133 @@
+package character
+
+import (
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// LevelingBuildCharacter takes stats, skills and key bindings from a leveling build template, everything related
+// to combat is done by the class routine defined in the template.
+type LevelingBuildCharacter struct {
+	context.Character
+	BaseCharacter
+	build *config.LevelingBuild
+}
+
+// AttackSkills forwards the skills declared by the combat routine, empty if it's not a multi element character
+func (s LevelingBuildCharacter) AttackSkills() []context.AttackSkill {
+	if mec, ok := s.Character.(context.MultiElementCharacter); ok {
+		return mec.AttackSkills()
+	}
+
+	return nil
+}
+
+// DamageResists forwards the immunities declared by the combat routine, empty if it doesn't declare them
+func (s LevelingBuildCharacter) DamageResists() []stat.Resist {
+	if drc, ok := s.Character.(context.DamageResistsCharacter); ok {
+		return drc.DamageResists()
+	}
+
+	return nil
+}
+
+func (s LevelingBuildCharacter) StatPoints() []context.StatTarget {
+	lvl, _ := s.Data.PlayerUnit.FindStat(stat.Level, 0)
+	statPoints := make([]context.StatTarget, 0)
+	for _, t := range s.build.StatTargets(lvl.Value) {
+		statPoints = append(statPoints, context.StatTarget{Stat: t.Stat, Points: t.Points})
+	}
+
+	s.Logger.Info("Assigning stat points", "level", lvl.Value, "statPoints", statPoints, "build", s.build.Name)
+	return statPoints
+}
+
+func (s LevelingBuildCharacter) SkillPoints() []skill.ID {
+	lvl, _ := s.Data.PlayerUnit.FindStat(stat.Level, 0)
+	skillPoints := s.build.SkillPoints(lvl.Value)
+
+	s.Logger.Info("Assigning skill points", "level", lvl.Value, "skillPoints", skillPoints, "build", s.build.Name)
+	return skillPoints
+}
+
+func (s LevelingBuildCharacter) SkillsToBind() (skill.ID, []skill.ID) {
+	lvl, _ := s.Data.PlayerUnit.FindStat(stat.Level, 0)
+	mainSkill, skillBindings := s.build.SkillBindings(lvl.Value)
+
+	// Skills without points can not be bound
+	learnedSkills := []skill.ID{skill.TomeOfTownPortal}
+	for _, sk := range skillBindings {
+		if s.Data.PlayerUnit.Skills[sk].Level > 0 {
+			learnedSkills = append(learnedSkills, sk)
+		}
+	}
+	if mainSkill != skill.AttackSkill && s.Data.PlayerUnit.Skills[mainSkill].Level == 0 {
+		mainSkill = skill.AttackSkill
+	}
+
+	s.Logger.Info("Skills bound", "mainSkill", mainSkill, "skillBindings", learnedSkills)
+	return mainSkill, learnedSkills
+}
+
+// ShouldResetSkills Once the respec level is reached, skills are reset if we have points in any class skill not
+// present in the current skill points order
+func (s LevelingBuildCharacter) ShouldResetSkills() bool {
+	lvl, _ := s.Data.PlayerUnit.FindStat(stat.Level, 0)
+	if s.build.RespecLevel == 0 || lvl.Value < s.build.RespecLevel {
+		return false
+	}
+
+	expectedPoints := make(map[skill.ID]int)
+	for _, sk := range s.build.SkillPoints(lvl.Value) {
+		expectedPoints[sk]++
+	}
+
+	for sk, points := range s.Data.PlayerUnit.Skills {
+		if _, isClassSkill := skill.Desc[sk]; !isClassSkill || points.Level == 0 {
+			continue
+		}
+
+		if _, found := expectedPoints[sk]; !found {
+			s.Logger.Info("Resetting skills: skill not present in the build", "skill", skill.SkillNames[sk], "level", lvl.Value)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s LevelingBuildCharacter) KillAncients() error {
+	if levelingChar, ok := s.Character.(context.LevelingCharacter); ok {
+		return levelingChar.KillAncients()
+	}
+
+	for _, m := range s.Data.Monsters.Enemies(data.MonsterEliteFilter()) {
+		err := s.KillMonsterSequence(func(d game.Data) (data.UnitID, bool) {
+			ancient, found := d.Monsters.FindOne(m.Name, data.MonsterTypeSuperUnique)
+			if !found || ancient.Stats[stat.Life] <= 0 {
+				return 0, false
+			}
+
+			return ancient.UnitID, true
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// berserkerLevelingBuild keeps the special berserk attack handling when the build combat routine is the berserker
+type berserkerLevelingBuild struct {
+	LevelingBuildCharacter
+	berserker *Berserker
+}
+
+func (s berserkerLevelingBuild) PerformBerserkAttack(monsterID data.UnitID) {
+	s.berserker.PerformBerserkAttack(monsterID)
+}
@@ -173,6 +173,11 @@ func (s LightningSorceress) killMonsterByName(id npc.ID, monsterType data.Monste
 	}, skipOnImmunities)
 }
 
+// DamageResists lightning immunes can not be damaged by chain lightning
+func (s LightningSorceress) DamageResists() []stat.Resist {
+	return []stat.Resist{stat.LightImmune}
+}
+
 func (s LightningSorceress) BuffSkills() []skill.ID {
 	skillsList := make([]skill.ID, 0)
 	if _, found := s.Data.KeyBindings.KeyBindingForSkill(skill.EnergyShield); found {
@@ -254,11 +259,16 @@ func (s LightningSorceress) KillIzual() error {
 
 func (s LightningSorceress) KillCouncil() error {
 	return s.KillMonsterSequence(func(d game.Data) (data.UnitID, bool) {
+		var councilMembers []data.Monster
 		for _, m := range d.Monsters.Enemies() {
 			if m.Name == npc.CouncilMember || m.Name == npc.CouncilMember2 || m.Name == npc.CouncilMember3 {
-				return m.UnitID, true
+				councilMembers = append(councilMembers, m)
 			}
 		}
+
+		if m, found := d.BestTarget(councilMembers, s.IsImmuneToCharacter); found {
+			return m.UnitID, true
+		}
 		return 0, false
 	}, nil)
 }
@@ -3,7 +3,6 @@ package character
 import (
 	"fmt"
 	"log/slog"
-	"sort"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
@@ -44,6 +43,7 @@ func (s MosaicSin) KillMonsterSequence(
 	ctx := context.Get()
 	ctx.RefreshGameData()
 	lastRefresh := time.Now()
+	attackSkills := s.AttackSkills()
 
 	for {
 		// Limit refresh rate to 10 times per second to avoid excessive CPU usage
@@ -112,7 +112,7 @@ func (s MosaicSin) KillMonsterSequence(
 		}
 
 		// Phoenix Strike - 2 charges
-		if !s.Data.PlayerUnit.States.HasState(state.Phoenixstrike) || (foundPhoenix && phoenixCharges.Value < 2) {
+		if canDamageWith(monster, attackSkills, skill.PhoenixStrike) && (!s.Data.PlayerUnit.States.HasState(state.Phoenixstrike) || (foundPhoenix && phoenixCharges.Value < 2)) {
 			step.SecondaryAttack(skill.PhoenixStrike, id, 1)
 			continue
 		}
@@ -122,7 +122,7 @@ func (s MosaicSin) KillMonsterSequence(
 		}
 
 		// Claws of Thunder - 3 charges
-		if ctx.CharacterCfg.Character.MosaicSin.UseClawsOfThunder {
+		if ctx.CharacterCfg.Character.MosaicSin.UseClawsOfThunder && canDamageWith(monster, attackSkills, skill.ClawsOfThunder) {
 			if !s.Data.PlayerUnit.States.HasState(state.Clawsofthunder) || (foundClaws && clawsCharges.Value < 3) {
 				step.SecondaryAttack(skill.ClawsOfThunder, id, 1)
 				continue
@@ -134,7 +134,7 @@ func (s MosaicSin) KillMonsterSequence(
 		}
 
 		// Blades of Ice - 3 charges
-		if ctx.CharacterCfg.Character.MosaicSin.UseBladesOfIce {
+		if ctx.CharacterCfg.Character.MosaicSin.UseBladesOfIce && canDamageWith(monster, attackSkills, skill.BladesOfIce) {
 			if !s.Data.PlayerUnit.States.HasState(state.Bladesofice) || (foundBlades && bladesCharges.Value < 3) {
 				step.SecondaryAttack(skill.BladesOfIce, id, 1)
 				continue
@@ -142,7 +142,7 @@ func (s MosaicSin) KillMonsterSequence(
 		}
 
 		// First of Fire - 3 charges
-		if ctx.CharacterCfg.Character.MosaicSin.UseFistsOfFire {
+		if ctx.CharacterCfg.Character.MosaicSin.UseFistsOfFire && canDamageWith(monster, attackSkills, skill.FistsOfFire) {
 			if !s.Data.PlayerUnit.States.HasState(state.Fistsoffire) || (foundFirst && firstCharges.Value < 3) {
 				step.SecondaryAttack(skill.FistsOfFire, id, 1)
 				continue
@@ -159,6 +159,22 @@ func (s MosaicSin) KillMonsterSequence(
 	}
 }
 
+// AttackSkills Only elemental charges are taken into account, charging an element the monster is immune to is a waste
+func (s MosaicSin) AttackSkills() []context.AttackSkill {
+	skills := make([]context.AttackSkill, 0)
+	if s.CharacterCfg.Character.MosaicSin.UseClawsOfThunder {
+		skills = append(skills, context.AttackSkill{Skill: skill.ClawsOfThunder, Resists: []stat.Resist{stat.LightImmune}})
+	}
+	if s.CharacterCfg.Character.MosaicSin.UseBladesOfIce {
+		skills = append(skills, context.AttackSkill{Skill: skill.BladesOfIce, Resists: []stat.Resist{stat.ColdImmune}})
+	}
+	if s.CharacterCfg.Character.MosaicSin.UseFistsOfFire {
+		skills = append(skills, context.AttackSkill{Skill: skill.FistsOfFire, Resists: []stat.Resist{stat.FireImmune}})
+	}
+
+	return append(skills, context.AttackSkill{Skill: skill.PhoenixStrike, Resists: []stat.Resist{stat.FireImmune, stat.LightImmune, stat.ColdImmune}})
+}
+
 func (s MosaicSin) MobAlive(mob data.UnitID, d game.Data) bool {
 	monster, found := s.Data.Monsters.FindByID(mob)
 	return found && monster.Stats[stat.Life] > 0
@@ -223,11 +239,8 @@ func (s MosaicSin) KillCouncil() error {
 			}
 		}
 
-		sort.Slice(councilMembers, func(i, j int) bool {
-			distanceI := s.PathFinder.DistanceFromMe(councilMembers[i].Position)
-			distanceJ := s.PathFinder.DistanceFromMe(councilMembers[j].Position)
-			return distanceI < distanceJ
-		})
+		// Order council members by target priority
+		d.SortByTargetPriority(councilMembers, s.IsImmuneToCharacter)
 
 		if len(councilMembers) > 0 {
 			return councilMembers[0].UnitID, true
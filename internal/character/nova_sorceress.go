@@ -95,6 +95,7 @@ func (s NovaSorceress) KillMonsterSequence(
 
 		novaOpts := []step.AttackOption{
 			step.RangedDistance(NovaMinDistance, NovaMaxDistance),
+			step.Kite(),
 		}
 
 		if err := step.SecondaryAttack(skill.Nova, monster.UnitID, 1, novaOpts...); err == nil {
@@ -161,6 +162,11 @@ func (s NovaSorceress) killMonsterByName(id npc.ID, monsterType data.MonsterType
 	}, skipOnImmunities)
 }
 
+// DamageResists lightning immunes can not be damaged by nova
+func (s NovaSorceress) DamageResists() []stat.Resist {
+	return []stat.Resist{stat.LightImmune}
+}
+
 func (s NovaSorceress) BuffSkills() []skill.ID {
 	skillsList := make([]skill.ID, 0)
 	if _, found := s.Data.KeyBindings.KeyBindingForSkill(skill.EnergyShield); found {
@@ -242,11 +248,16 @@ func (s NovaSorceress) KillIzual() error {
 
 func (s NovaSorceress) KillCouncil() error {
 	return s.KillMonsterSequence(func(d game.Data) (data.UnitID, bool) {
+		var councilMembers []data.Monster
 		for _, m := range d.Monsters.Enemies() {
 			if m.Name == npc.CouncilMember || m.Name == npc.CouncilMember2 || m.Name == npc.CouncilMember3 {
-				return m.UnitID, true
+				councilMembers = append(councilMembers, m)
 			}
 		}
+
+		if m, found := d.BestTarget(councilMembers, s.IsImmuneToCharacter); found {
+			return m.UnitID, true
+		}
 		return 0, false
 	}, nil)
 }
@@ -3,10 +3,10 @@ package character
 import (
 	"fmt"
 	"log/slog"
-	"sort"
 	"time"
 
 	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/game"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
@@ -164,29 +164,37 @@ func (s PaladinLeveling) SkillsToBind() (skill.ID, []skill.ID) {
 	return mainSkill, skillBindings
 }
 
-func (s PaladinLeveling) StatPoints() map[stat.ID]int {
+func (s PaladinLeveling) StatPoints() []context.StatTarget {
 	lvl, _ := s.Data.PlayerUnit.FindStat(stat.Level, 0)
-	statPoints := make(map[stat.ID]int)
+	var statPoints []context.StatTarget
 
 	if lvl.Value < 21 {
-		statPoints[stat.Strength] = 0
-		statPoints[stat.Dexterity] = 25
-		statPoints[stat.Vitality] = 150
-		statPoints[stat.Energy] = 0
+		statPoints = []context.StatTarget{
+			{Stat: stat.Strength, Points: 0},
+			{Stat: stat.Dexterity, Points: 25},
+			{Stat: stat.Vitality, Points: 150},
+			{Stat: stat.Energy, Points: 0},
+		}
 	} else if lvl.Value < 30 {
-		statPoints[stat.Strength] = 35
-		statPoints[stat.Vitality] = 200
-		statPoints[stat.Energy] = 0
+		statPoints = []context.StatTarget{
+			{Stat: stat.Strength, Points: 35},
+			{Stat: stat.Vitality, Points: 200},
+			{Stat: stat.Energy, Points: 0},
+		}
 	} else if lvl.Value < 45 {
-		statPoints[stat.Strength] = 50
-		statPoints[stat.Dexterity] = 40
-		statPoints[stat.Vitality] = 220
-		statPoints[stat.Energy] = 0
+		statPoints = []context.StatTarget{
+			{Stat: stat.Strength, Points: 50},
+			{Stat: stat.Dexterity, Points: 40},
+			{Stat: stat.Vitality, Points: 220},
+			{Stat: stat.Energy, Points: 0},
+		}
 	} else {
-		statPoints[stat.Strength] = 86
-		statPoints[stat.Dexterity] = 50
-		statPoints[stat.Vitality] = 300
-		statPoints[stat.Energy] = 0
+		statPoints = []context.StatTarget{
+			{Stat: stat.Strength, Points: 86},
+			{Stat: stat.Dexterity, Points: 50},
+			{Stat: stat.Vitality, Points: 300},
+			{Stat: stat.Energy, Points: 0},
+		}
 	}
 
 	s.Logger.Info("Assigning stat points", "level", lvl.Value, "statPoints", statPoints)
@@ -344,13 +352,8 @@ func (s PaladinLeveling) KillCouncil() error {
 			}
 		}
 
-		// Order council members by distance
-		sort.Slice(councilMembers, func(i, j int) bool {
-			distanceI := s.PathFinder.DistanceFromMe(councilMembers[i].Position)
-			distanceJ := s.PathFinder.DistanceFromMe(councilMembers[j].Position)
-
-			return distanceI < distanceJ
-		})
+		// Order council members by target priority
+		d.SortByTargetPriority(councilMembers, s.IsImmuneToCharacter)
 
 		if len(councilMembers) > 0 {
 			s.Logger.Debug("Targeting Council member", "id", councilMembers[0].UnitID)
@@ -3,7 +3,6 @@ package character
 import (
 	"fmt"
 	"log/slog"
-	"sort"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
@@ -189,16 +188,20 @@ func (s SorceressLeveling) SkillsToBind() (skill.ID, []skill.ID) {
 	return mainSkill, skillBindings
 }
 
-func (s SorceressLeveling) StatPoints() map[stat.ID]int {
+func (s SorceressLeveling) StatPoints() []context.StatTarget {
 	lvl, _ := s.Data.PlayerUnit.FindStat(stat.Level, 0)
-	statPoints := make(map[stat.ID]int)
+	var statPoints []context.StatTarget
 
 	if lvl.Value < 20 {
-		statPoints[stat.Vitality] = 9999
+		statPoints = []context.StatTarget{
+			{Stat: stat.Vitality, Points: 9999},
+		}
 	} else {
-		statPoints[stat.Energy] = 80
-		statPoints[stat.Strength] = 60
-		statPoints[stat.Vitality] = 9999
+		statPoints = []context.StatTarget{
+			{Stat: stat.Energy, Points: 80},
+			{Stat: stat.Strength, Points: 60},
+			{Stat: stat.Vitality, Points: 9999},
+		}
 	}
 
 	s.Logger.Info("Assigning stat points", "level", lvl.Value, "statPoints", statPoints)
@@ -332,13 +335,8 @@ func (s SorceressLeveling) KillCouncil() error {
 			}
 		}
 
-		// Order council members by distance
-		sort.Slice(councilMembers, func(i, j int) bool {
-			distanceI := s.PathFinder.DistanceFromMe(councilMembers[i].Position)
-			distanceJ := s.PathFinder.DistanceFromMe(councilMembers[j].Position)
-
-			return distanceI < distanceJ
-		})
+		// Order council members by target priority
+		d.SortByTargetPriority(councilMembers, s.IsImmuneToCharacter)
 
 		for _, m := range councilMembers {
 			return m.UnitID, true
@@ -3,7 +3,6 @@ package character
 import (
 	"fmt"
 	"log/slog"
-	"sort"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
@@ -206,20 +205,26 @@ func (s SorceressLevelingLightning) SkillsToBind() (skill.ID, []skill.ID) {
 	return mainSkill, skillBindings
 }
 
-func (s SorceressLevelingLightning) StatPoints() map[stat.ID]int {
+func (s SorceressLevelingLightning) StatPoints() []context.StatTarget {
 	lvl, _ := s.Data.PlayerUnit.FindStat(stat.Level, 0)
-	statPoints := make(map[stat.ID]int)
+	var statPoints []context.StatTarget
 
 	if lvl.Value < 9 {
-		statPoints[stat.Vitality] = 9999
+		statPoints = []context.StatTarget{
+			{Stat: stat.Vitality, Points: 9999},
+		}
 	} else if lvl.Value < 15 {
-		statPoints[stat.Energy] = 45
-		statPoints[stat.Strength] = 25
-		statPoints[stat.Vitality] = 9999
+		statPoints = []context.StatTarget{
+			{Stat: stat.Energy, Points: 45},
+			{Stat: stat.Strength, Points: 25},
+			{Stat: stat.Vitality, Points: 9999},
+		}
 	} else {
-		statPoints[stat.Energy] = 60
-		statPoints[stat.Strength] = 50
-		statPoints[stat.Vitality] = 9999
+		statPoints = []context.StatTarget{
+			{Stat: stat.Energy, Points: 60},
+			{Stat: stat.Strength, Points: 50},
+			{Stat: stat.Vitality, Points: 9999},
+		}
 	}
 
 	s.Logger.Info("Assigning stat points", "level", lvl.Value, "statPoints", statPoints)
@@ -366,13 +371,8 @@ func (s SorceressLevelingLightning) KillCouncil() error {
 			}
 		}
 
-		// Order council members by distance
-		sort.Slice(councilMembers, func(i, j int) bool {
-			distanceI := s.PathFinder.DistanceFromMe(councilMembers[i].Position)
-			distanceJ := s.PathFinder.DistanceFromMe(councilMembers[j].Position)
-
-			return distanceI < distanceJ
-		})
+		// Order council members by target priority
+		d.SortByTargetPriority(councilMembers, s.IsImmuneToCharacter)
 
 		for _, m := range councilMembers {
 			return m.UnitID, true
@@ -3,7 +3,6 @@ package character
 import (
 	"fmt"
 	"log/slog"
-	"sort"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
@@ -75,8 +74,8 @@ func (s Trapsin) KillMonsterSequence(
 		opts := step.Distance(minDistance, maxDistance)
 
 		utils.Sleep(100)
-		step.SecondaryAttack(skill.LightningSentry, id, 3, opts)
-		step.SecondaryAttack(skill.DeathSentry, id, 2, opts)
+		step.SecondaryAttack(skill.LightningSentry, id, 3, opts, step.Kite())
+		step.SecondaryAttack(skill.DeathSentry, id, 2, opts, step.Kite())
 		step.PrimaryAttack(id, 2, true, opts)
 
 		completedAttackLoops++
@@ -95,6 +94,11 @@ func (s Trapsin) killMonster(npc npc.ID, t data.MonsterType) error {
 	}, nil)
 }
 
+// DamageResists lightning sentry and death sentry fire explosion
+func (s Trapsin) DamageResists() []stat.Resist {
+	return []stat.Resist{stat.LightImmune, stat.FireImmune}
+}
+
 func (s Trapsin) BuffSkills() []skill.ID {
 	armor := skill.Fade
 	armors := []skill.ID{skill.BurstOfSpeed, skill.Fade}
@@ -155,13 +159,8 @@ func (s Trapsin) KillCouncil() error {
 			}
 		}
 
-		// Order council members by distance
-		sort.Slice(councilMembers, func(i, j int) bool {
-			distanceI := s.PathFinder.DistanceFromMe(councilMembers[i].Position)
-			distanceJ := s.PathFinder.DistanceFromMe(councilMembers[j].Position)
-
-			return distanceI < distanceJ
-		})
+		// Order council members by target priority
+		d.SortByTargetPriority(councilMembers, s.IsImmuneToCharacter)
 
 		for _, m := range councilMembers {
 			return m.UnitID, true
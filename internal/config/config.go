@@ -58,6 +58,10 @@ type KooloCfg struct {
 		ChatID  int64  `yaml:"chatId"`
 		Token   string `yaml:"token"`
 	}
+	Party struct {
+		// Secret shared by the koolo instances of the party, followers from other machines can not connect without it
+		Secret string `yaml:"secret"`
+	} `yaml:"party"`
 }
 
 type Day struct {
@@ -110,6 +114,7 @@ type CharacterCfg struct {
 		UseMerc       bool   `yaml:"useMerc"`
 		StashToShared bool   `yaml:"stashToShared"`
 		UseTeleport   bool   `yaml:"useTeleport"`
+		LevelingBuild string `yaml:"levelingBuild"`
 		BerserkerBarb struct {
 			FindItemSwitch              bool `yaml:"find_item_switch"`
 			SkipPotionPickupInTravincal bool `yaml:"skip_potion_pickup_in_travincal"`
@@ -124,6 +129,21 @@ type CharacterCfg struct {
 			UseBladesOfIce    bool `yaml:"useBladesOfIce"`
 			UseFistsOfFire    bool `yaml:"useFistsOfFire"`
 		} `yaml:"mosaic_sin"`
+		Kiting struct {
+			Enabled     bool `yaml:"enabled"`
+			MinDistance int  `yaml:"minDistance"`
+			MaxDistance int  `yaml:"maxDistance"`
+		} `yaml:"kiting"`
+		Targeting struct {
+			BossWeight      int `yaml:"bossWeight"`
+			ChampionWeight  int `yaml:"championWeight"`
+			MinionWeight    int `yaml:"minionWeight"`
+			RaiserWeight    int `yaml:"raiserWeight"`
+			AuraWeight      int `yaml:"auraWeight"`
+			CursedWeight    int `yaml:"cursedWeight"`
+			LowHealthWeight int `yaml:"lowHealthWeight"`
+			DistanceWeight  int `yaml:"distanceWeight"`
+		} `yaml:"targeting"`
 	} `yaml:"character"`
 
 	Game struct {
@@ -133,9 +153,19 @@ type CharacterCfg struct {
 		Difficulty             difficulty.Difficulty `yaml:"difficulty"`
 		RandomizeRuns          bool                  `yaml:"randomizeRuns"`
 		Runs                   []Run                 `yaml:"runs"`
+		RunPolicies            map[Run]RunPolicy     `yaml:"runPolicies"`
 		CreateLobbyGames       bool                  `yaml:"createLobbyGames"`
-		PublicGameCounter      int                   `yaml:"-"`
-		Pindleskin             struct {
+		JoinGames              struct {
+			Enabled             bool                  `yaml:"enabled"`
+			NamePattern         string                `yaml:"namePattern"`
+			Difficulty          difficulty.Difficulty `yaml:"difficulty"`
+			MinPlayers          int                   `yaml:"minPlayers"`
+			MaxPlayers          int                   `yaml:"maxPlayers"`
+			MaxLevelRequirement int                   `yaml:"maxLevelRequirement"`
+			BlacklistMinutes    int                   `yaml:"blacklistMinutes"`
+			Runs                []Run                 `yaml:"runs"`
+		} `yaml:"joinGames"`
+		Pindleskin struct {
 			SkipOnImmunities []stat.Resist `yaml:"skipOnImmunities"`
 		} `yaml:"pindleskin"`
 		Cows struct {
@@ -206,6 +236,12 @@ type CharacterCfg struct {
 		LowerKurastChest struct {
 			OpenRacks bool `yaml:"openRacks"`
 		} `yaml:"lowerkurastchests"`
+		Shopping struct {
+			Enabled        bool     `yaml:"enabled"`
+			MinGoldReserve int      `yaml:"minGoldReserve"`
+			Iterations     int      `yaml:"iterations"`
+			Vendors        []string `yaml:"vendors"`
+		} `yaml:"shopping"`
 		TerrorZone struct {
 			FocusOnElitePacks bool          `yaml:"focusOnElitePacks"`
 			SkipOnImmunities  []stat.Resist `yaml:"skipOnImmunities"`
@@ -216,6 +252,15 @@ type CharacterCfg struct {
 		Leveling struct {
 			EnsurePointsAllocation bool `yaml:"ensurePointsAllocation"`
 			EnsureKeyBinding       bool `yaml:"ensureKeyBinding"`
+			AutoEquip              bool `yaml:"autoEquip"`
+			Handover               struct {
+				Enabled         bool                  `yaml:"enabled"`
+				Level           int                   `yaml:"level"`
+				Difficulty      difficulty.Difficulty `yaml:"difficulty"`
+				RequireAct5Done bool                  `yaml:"requireAct5Done"`
+				Class           string                `yaml:"class"`
+				Runs            []Run                 `yaml:"runs"`
+			} `yaml:"handover"`
 		} `yaml:"leveling"`
 		Quests struct {
 			ClearDen       bool `yaml:"clearDen"`
@@ -231,20 +276,52 @@ type CharacterCfg struct {
 		} `yaml:"quests"`
 	} `yaml:"game"`
 	Companion struct {
-		Leader           bool   `yaml:"leader"`
-		LeaderName       string `yaml:"leaderName"`
-		GameNameTemplate string `yaml:"gameNameTemplate"`
-		GamePassword     string `yaml:"gamePassword"`
+		Enabled              bool                 `yaml:"enabled"`
+		Leader               bool                 `yaml:"leader"`
+		LeaderName           string               `yaml:"leaderName"`
+		GameNameTemplate     string               `yaml:"gameNameTemplate"`
+		GameNameStrategy     GameNameStrategy     `yaml:"gameNameStrategy"`
+		GamePassword         string               `yaml:"gamePassword"`
+		GamePasswordStrategy GamePasswordStrategy `yaml:"gamePasswordStrategy"`
+		FollowLeader         bool                 `yaml:"followLeader"`
+		FollowDistance       int                  `yaml:"followDistance"`
+		Attack               bool                 `yaml:"attack"`
+		BuffInterval         int                  `yaml:"buffInterval"` // Seconds between party buffs (BO, Oak...)
+		// LeaderAddress is the koolo web server of the leader when it's running in another machine (e.g. 192.168.1.10:8087)
+		LeaderAddress string `yaml:"leaderAddress"`
 	} `yaml:"companion"`
+	BattleNet struct {
+		MaxFailures int `yaml:"maxFailures"` // Consecutive bnet failures before restarting the client, 0 means never
+		BackoffBase int `yaml:"backoffBase"` // Seconds to wait after the first failure, doubled on every failure
+		BackoffMax  int `yaml:"backoffMax"`  // Max seconds to wait between retries
+	} `yaml:"battleNet"`
+	Restart struct {
+		MaxRestarts   int `yaml:"maxRestarts"` // Max client restarts in the window, next ones will wait. 0 means no limit
+		WindowMinutes int `yaml:"windowMinutes"`
+		BackoffBase   int `yaml:"backoffBase"` // Seconds to wait before the first restart, doubled on every consecutive crash
+		BackoffMax    int `yaml:"backoffMax"`
+		GiveUpAfter   int `yaml:"giveUpAfter"` // Consecutive crashes without creating a game before giving up. 0 means never
+	} `yaml:"restart"`
+	Watchdog struct {
+		Enabled      bool `yaml:"enabled"`
+		StallSeconds int  `yaml:"stallSeconds"` // Seconds without any progress before trying to recover
+	} `yaml:"watchdog"`
 	Gambling struct {
-		Enabled bool        `yaml:"enabled"`
-		Items   []item.Name `yaml:"items"`
+		Enabled       bool        `yaml:"enabled"`
+		Items         []item.Name `yaml:"items"`
+		StartGold     int         `yaml:"startGold"`
+		StopGold      int         `yaml:"stopGold"`
+		SessionBudget int         `yaml:"sessionBudget"`
+		StopLoss      int         `yaml:"stopLoss"`
+		KeepQualities []string    `yaml:"keepQualities"`
 	} `yaml:"gambling"`
 	CubeRecipes struct {
 		Enabled              bool     `yaml:"enabled"`
 		EnabledRecipes       []string `yaml:"enabledRecipes"`
 		SkipPerfectAmethysts bool     `yaml:"skipPerfectAmethysts"`
 		SkipPerfectRubies    bool     `yaml:"skipPerfectRubies"`
+		// Crafter cubes the recipes with the items in the shared stash for all the characters of the same account
+		Crafter bool `yaml:"crafter"`
 	} `yaml:"cubing"`
 	BackToTown struct {
 		NoHpPotions     bool `yaml:"noHpPotions"`
@@ -253,8 +330,12 @@ type CharacterCfg struct {
 		EquipmentBroken bool `yaml:"equipmentBroken"`
 	} `yaml:"backtotown"`
 	Runtime struct {
-		Rules nip.Rules   `yaml:"-"`
-		Drops []data.Item `yaml:"-"`
+		Rules         nip.Rules              `yaml:"-"`
+		ShoppingRules nip.Rules              `yaml:"-"`
+		GamblingRules nip.Rules              `yaml:"-"`
+		CubeRecipes   []CubeRecipeDefinition `yaml:"-"`
+		Drops         []data.Item            `yaml:"-"`
+		LevelingBuild *LevelingBuild         `yaml:"-"`
 	} `yaml:"-"`
 }
 
@@ -320,7 +401,7 @@ func Load() error {
 			continue
 		}
 
-		charCfg := CharacterCfg{}
+		charCfg := newCharacterCfg()
 
 		// Load character config from the current working directory/config/{charName}/config.yaml
 		charConfigPath := getAbsPath(filepath.Join("config", entry.Name(), "config.yaml"))
@@ -367,9 +448,50 @@ func Load() error {
 				return fmt.Errorf("error reading pickit_leveling directory %s: %w", levelingPickitPath, err)
 			}
 			rules = append(rules, levelingRules...)
+
+			// Load the leveling build template from the current dir/config/{charName}/builds/{build}.yaml
+			if charCfg.Character.LevelingBuild != "" {
+				buildPath := getAbsPath(filepath.Join("config", entry.Name(), "builds", charCfg.Character.LevelingBuild+".yaml"))
+				build, err := LoadLevelingBuild(buildPath)
+				if err != nil {
+					return err
+				}
+				charCfg.Runtime.LevelingBuild = build
+			}
 		}
 
 		charCfg.Runtime.Rules = rules
+
+		// Load the shopping rules, used to buy items from vendors, this directory is optional
+		shoppingPath := getAbsPath(filepath.Join("config", entry.Name(), "shopping")) + "\\"
+		if _, err := os.Stat(shoppingPath); err == nil {
+			shoppingRules, err := nip.ReadDir(shoppingPath)
+			if err != nil {
+				return fmt.Errorf("error reading shopping directory %s: %w", shoppingPath, err)
+			}
+			charCfg.Runtime.ShoppingRules = shoppingRules
+		}
+
+		// Load the gambling rules, if not present the pickit rules will be used to evaluate gambled items
+		gamblingPath := getAbsPath(filepath.Join("config", entry.Name(), "gambling")) + "\\"
+		if _, err := os.Stat(gamblingPath); err == nil {
+			gamblingRules, err := nip.ReadDir(gamblingPath)
+			if err != nil {
+				return fmt.Errorf("error reading gambling directory %s: %w", gamblingPath, err)
+			}
+			charCfg.Runtime.GamblingRules = gamblingRules
+		}
+
+		// Load the user defined cube recipes, this file is optional
+		cubeRecipesPath := getAbsPath(filepath.Join("config", entry.Name(), "cube_recipes.yaml"))
+		if _, err := os.Stat(cubeRecipesPath); err == nil {
+			cubeRecipes, err := LoadCubeRecipes(cubeRecipesPath)
+			if err != nil {
+				return err
+			}
+			charCfg.Runtime.CubeRecipes = cubeRecipes
+		}
+
 		Characters[entry.Name()] = &charCfg
 	}
 
@@ -441,6 +563,17 @@ func SaveSupervisorConfig(supervisorName string, config *CharacterCfg) error {
 	return Load()
 }
 
+// newCharacterCfg returns the defaults for the settings where 0 is a valid value, they are only used when the key is
+// missing in the config file, Validate can not tell them apart from a 0 set by the user
+func newCharacterCfg() CharacterCfg {
+	cfg := CharacterCfg{}
+	cfg.Gambling.StopGold = 500000
+	cfg.Restart.MaxRestarts = 3
+	cfg.Restart.GiveUpAfter = 10
+
+	return cfg
+}
+
 func (c *CharacterCfg) Validate() {
 	if c.Character.Class == "nova" || c.Character.Class == "lightsorc" {
 		minThreshold := 65 // Default
@@ -456,4 +589,89 @@ func (c *CharacterCfg) Validate() {
 			c.Character.NovaSorceress.BossStaticThreshold = minThreshold
 		}
 	}
+
+	if c.Character.Kiting.Enabled {
+		if c.Character.Kiting.MinDistance <= 0 {
+			c.Character.Kiting.MinDistance = 8
+		}
+		if c.Character.Kiting.MaxDistance <= c.Character.Kiting.MinDistance {
+			c.Character.Kiting.MaxDistance = c.Character.Kiting.MinDistance + 7
+		}
+	}
+
+	if c.Gambling.StartGold <= 0 {
+		c.Gambling.StartGold = 2480000
+	}
+	// 0 is allowed, gambling only stops because of the budget, stop-loss or not having more gold
+	if c.Gambling.StopGold < 0 {
+		c.Gambling.StopGold = 0
+	}
+
+	if c.Companion.GameNameStrategy == "" {
+		c.Companion.GameNameStrategy = GameNameCounter
+	}
+	if c.Companion.GamePasswordStrategy == "" {
+		c.Companion.GamePasswordStrategy = GamePasswordFixed
+	}
+
+	// 0 is allowed, the client is never restarted
+	if c.BattleNet.MaxFailures < 0 {
+		c.BattleNet.MaxFailures = 5
+	}
+	if c.BattleNet.BackoffBase <= 0 {
+		c.BattleNet.BackoffBase = 30
+	}
+	if c.BattleNet.BackoffMax < c.BattleNet.BackoffBase {
+		c.BattleNet.BackoffMax = max(600, c.BattleNet.BackoffBase)
+	}
+
+	if c.Restart.MaxRestarts < 0 {
+		c.Restart.MaxRestarts = 0
+	}
+	if c.Restart.WindowMinutes <= 0 {
+		c.Restart.WindowMinutes = 15
+	}
+	if c.Restart.BackoffBase <= 0 {
+		c.Restart.BackoffBase = 5
+	}
+	if c.Restart.BackoffMax < c.Restart.BackoffBase {
+		c.Restart.BackoffMax = max(300, c.Restart.BackoffBase)
+	}
+	if c.Restart.GiveUpAfter < 0 {
+		c.Restart.GiveUpAfter = 0
+	}
+
+	if c.Watchdog.StallSeconds <= 0 {
+		c.Watchdog.StallSeconds = 60
+	}
+
+	if c.Companion.FollowDistance <= 0 {
+		c.Companion.FollowDistance = 8
+	}
+	if c.Companion.BuffInterval <= 0 {
+		c.Companion.BuffInterval = 60
+	}
+
+	if c.Game.JoinGames.BlacklistMinutes <= 0 {
+		c.Game.JoinGames.BlacklistMinutes = 30
+	}
+
+	if c.Game.Shopping.Iterations <= 0 {
+		c.Game.Shopping.Iterations = 50
+	}
+	if len(c.Game.Shopping.Vendors) == 0 {
+		c.Game.Shopping.Vendors = []string{"anya", "drognan", "ormus", "fara"}
+	}
+
+	// Targeting weights not set at all, let's use the defaults
+	if c.Character.Targeting == (CharacterCfg{}).Character.Targeting {
+		c.Character.Targeting.BossWeight = 30
+		c.Character.Targeting.ChampionWeight = 15
+		c.Character.Targeting.MinionWeight = 10
+		c.Character.Targeting.RaiserWeight = 40
+		c.Character.Targeting.AuraWeight = 10
+		c.Character.Targeting.CursedWeight = 5
+		c.Character.Targeting.LowHealthWeight = 20
+		c.Character.Targeting.DistanceWeight = 2
+	}
 }
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGamblingStopGoldDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected int
+	}{
+		{name: "Missing key uses the default", content: "gambling:\n  enabled: true\n", expected: 500000},
+		{name: "Disabled", content: "gambling:\n  stopGold: 0\n", expected: 0},
+		{name: "Custom value", content: "gambling:\n  stopGold: 100000\n", expected: 100000},
+		{name: "Negative values are disabled", content: "gambling:\n  stopGold: -1\n", expected: 0},
+	}
+
+	for _, tt := range tests {
+		cfg := newCharacterCfg()
+		if err := yaml.Unmarshal([]byte(tt.content), &cfg); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		cfg.Validate()
+
+		if cfg.Gambling.StopGold != tt.expected {
+			t.Errorf("%s: expected stop gold %d, got %d", tt.name, tt.expected, cfg.Gambling.StopGold)
+		}
+	}
+}
+
+func TestRestartLimitsDefault(t *testing.T) {
+	tests := []struct {
+		name                string
+		content             string
+		expectedMaxRestarts int
+		expectedGiveUpAfter int
+	}{
+		{name: "Missing keys use the defaults", content: "restart:\n  windowMinutes: 15\n", expectedMaxRestarts: 3, expectedGiveUpAfter: 10},
+		{name: "Disabled", content: "restart:\n  maxRestarts: 0\n  giveUpAfter: 0\n", expectedMaxRestarts: 0, expectedGiveUpAfter: 0},
+		{name: "Custom values", content: "restart:\n  maxRestarts: 5\n  giveUpAfter: 2\n", expectedMaxRestarts: 5, expectedGiveUpAfter: 2},
+		{name: "Negative values are disabled", content: "restart:\n  maxRestarts: -1\n  giveUpAfter: -1\n", expectedMaxRestarts: 0, expectedGiveUpAfter: 0},
+	}
+
+	for _, tt := range tests {
+		cfg := newCharacterCfg()
+		if err := yaml.Unmarshal([]byte(tt.content), &cfg); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		cfg.Validate()
+
+		if cfg.Restart.MaxRestarts != tt.expectedMaxRestarts || cfg.Restart.GiveUpAfter != tt.expectedGiveUpAfter {
+			t.Errorf("%s: expected max restarts %d and give up after %d, got %d and %d", tt.name,
+				tt.expectedMaxRestarts, tt.expectedGiveUpAfter, cfg.Restart.MaxRestarts, cfg.Restart.GiveUpAfter)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"gopkg.in/yaml.v3"
+)
+
+// Horadric Cube is 3x4
+const cubeSize = 12
+
+var cubeRecipeQualities = map[string]item.Quality{
+	"lowquality": item.QualityLowQuality,
+	"normal":     item.QualityNormal,
+	"superior":   item.QualitySuperior,
+	"magic":      item.QualityMagic,
+	"set":        item.QualitySet,
+	"rare":       item.QualityRare,
+	"unique":     item.QualityUnique,
+	"crafted":    item.QualityCrafted,
+}
+
+// CubeRecipeDefinition is a user defined cube recipe, loaded from config/{character}/cube_recipes.yaml
+type CubeRecipeDefinition struct {
+	Name     string             `yaml:"name"`
+	Inputs   []CubeRecipeInput  `yaml:"inputs"`
+	Purchase CubeRecipePurchase `yaml:"purchase"`
+	// Output is a NIP rule the transmuted item is expected to match, optional
+	Output string `yaml:"output"`
+
+	outputRule *nip.Rule
+}
+
+// CubeRecipeInput can be an item name, a NIP rule or both, NIP rules allow filtering by quality, ethereal
+// ([flag] == ethereal) or sockets ([sockets] == 3) among others. Item level is not available.
+type CubeRecipeInput struct {
+	Name     string `yaml:"name"`
+	Rule     string `yaml:"rule"`
+	Quantity int    `yaml:"quantity"`
+
+	rule *nip.Rule
+}
+
+// CubeRecipePurchase defines an item that is not taken from the stash, it will be gambled until one of the items with
+// the given quality is found, usually the magic base for crafting recipes
+type CubeRecipePurchase struct {
+	Items   []string `yaml:"items"`
+	Quality string   `yaml:"quality"` // Defaults to magic
+}
+
+func LoadCubeRecipes(path string) ([]CubeRecipeDefinition, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading cube recipes: %w", err)
+	}
+	defer r.Close()
+
+	file := struct {
+		Recipes []CubeRecipeDefinition `yaml:"recipes"`
+	}{}
+	if err = yaml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("error reading cube recipes %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(file.Recipes))
+	for i := range file.Recipes {
+		recipe := &file.Recipes[i]
+		if err = recipe.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid cube recipe %q in %s: %w", recipe.Name, path, err)
+		}
+
+		if slices.Contains(names, recipe.Name) || slices.Contains(AvailableRecipes, recipe.Name) {
+			return nil, fmt.Errorf("duplicated cube recipe name %q in %s", recipe.Name, path)
+		}
+		names = append(names, recipe.Name)
+	}
+
+	return file.Recipes, nil
+}
+
+// Validate compiles the rules and checks the recipe can be done, items exist and fit in the cube
+func (r *CubeRecipeDefinition) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if len(r.Inputs) == 0 {
+		return errors.New("at least one input is required")
+	}
+
+	usedCells := 0
+	for i := range r.Inputs {
+		in := &r.Inputs[i]
+		if in.Quantity == 0 {
+			in.Quantity = 1
+		}
+		if in.Quantity < 0 {
+			return fmt.Errorf("input %d: quantity can not be negative", i+1)
+		}
+
+		if in.Name == "" && in.Rule == "" {
+			return fmt.Errorf("input %d: name or rule is required", i+1)
+		}
+
+		// Items matching a rule can be of any size, we count at least one cell
+		cells := 1
+		if in.Name != "" {
+			id := item.GetIDByName(in.Name)
+			if id == -1 {
+				return fmt.Errorf("input %d: unknown item %s", i+1, in.Name)
+			}
+			desc := item.Desc[id]
+			cells = max(1, desc.InventoryWidth*desc.InventoryHeight)
+		}
+		usedCells += cells * in.Quantity
+
+		if in.Rule != "" {
+			rule, err := compileCubeRecipeRule(in.Rule, r.Name)
+			if err != nil {
+				return fmt.Errorf("input %d: %w", i+1, err)
+			}
+			in.rule = &rule
+		}
+	}
+
+	if len(r.Purchase.Items) > 0 {
+		if r.Purchase.Quality == "" {
+			r.Purchase.Quality = "magic"
+		}
+		for _, name := range r.Purchase.Items {
+			id := item.GetIDByName(name)
+			if id == -1 {
+				return fmt.Errorf("unknown purchase item %s", name)
+			}
+		}
+		if _, found := cubeRecipeQualities[strings.ToLower(r.Purchase.Quality)]; !found {
+			return fmt.Errorf("unknown purchase quality %q", r.Purchase.Quality)
+		}
+
+		// Bases for crafting can be big, use the biggest one
+		biggest := 0
+		for _, name := range r.Purchase.Items {
+			desc := item.Desc[item.GetIDByName(name)]
+			biggest = max(biggest, desc.InventoryWidth*desc.InventoryHeight)
+		}
+		usedCells += biggest
+	}
+
+	if usedCells > cubeSize {
+		return fmt.Errorf("inputs need %d cells but the cube only has %d", usedCells, cubeSize)
+	}
+
+	if r.Output != "" {
+		rule, err := compileCubeRecipeRule(r.Output, r.Name)
+		if err != nil {
+			return fmt.Errorf("output: %w", err)
+		}
+		r.outputRule = &rule
+	}
+
+	return nil
+}
+
+// PurchaseQuality returns the expected quality of the purchased item
+func (r CubeRecipeDefinition) PurchaseQuality() item.Quality {
+	return cubeRecipeQualities[strings.ToLower(r.Purchase.Quality)]
+}
+
+// OutputMatches returns true if the item matches the expected output, or if no output is defined
+func (r CubeRecipeDefinition) OutputMatches(i data.Item) bool {
+	if r.outputRule == nil {
+		return true
+	}
+
+	res, err := r.outputRule.Evaluate(i)
+
+	return err == nil && res != nip.RuleResultNoMatch
+}
+
+// Matches returns true if the item can be used as this input
+func (in CubeRecipeInput) Matches(i data.Item) bool {
+	if in.Name != "" && !strings.EqualFold(string(i.Name), in.Name) {
+		return false
+	}
+
+	if in.rule != nil {
+		res, err := in.rule.Evaluate(i)
+		return err == nil && res == nip.RuleResultFullMatch
+	}
+
+	return true
+}
+
+func compileCubeRecipeRule(raw, recipeName string) (nip.Rule, error) {
+	// Rules are evaluated in two stages and the first one is mandatory, see nip.Rule
+	if strings.TrimSpace(strings.Split(raw, "#")[0]) == "" {
+		return nip.Rule{}, fmt.Errorf("rule %q needs at least one item property before #", raw)
+	}
+
+	rule, err := nip.NewRule(raw, "cube_recipes.yaml: "+recipeName, 0)
+	if err != nil {
+		return nip.Rule{}, fmt.Errorf("invalid rule %q: %w", raw, err)
+	}
+
+	return rule, nil
+}
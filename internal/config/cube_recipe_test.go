@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+)
+
+const (
+	testQuiltedArmorID = 313
+	testAmuletID       = 520
+	testJewelID        = 643
+)
+
+func writeCubeRecipes(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "cube_recipes.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadCubeRecipesTemplate(t *testing.T) {
+	recipes, err := LoadCubeRecipes(filepath.Join("..", "..", "config", "template", "cube_recipes.yaml"))
+	if err != nil {
+		t.Fatalf("template recipes should be valid: %v", err)
+	}
+	if len(recipes) != 2 {
+		t.Fatalf("expected 2 recipes, got %d", len(recipes))
+	}
+
+	amulet := recipes[1]
+	if amulet.PurchaseQuality() != item.QualityMagic || amulet.Inputs[0].Quantity != 1 {
+		t.Errorf("expected purchase quality and quantity defaults, got %+v", amulet)
+	}
+
+	magicJewel := data.Item{ID: testJewelID, Name: "Jewel", Quality: item.QualityMagic, Identified: true}
+	if !amulet.Inputs[2].Matches(magicJewel) {
+		t.Error("magic jewel should match the jewel input")
+	}
+	magicJewel.Quality = item.QualityRare
+	if amulet.Inputs[2].Matches(magicJewel) {
+		t.Error("rare jewel should not match the jewel input")
+	}
+	if amulet.Inputs[0].Matches(data.Item{Name: "TalRune"}) {
+		t.Error("inputs defined by name should only match that item")
+	}
+
+	craftedAmulet := data.Item{ID: testAmuletID, Name: "Amulet", Quality: item.QualityCrafted, Identified: true}
+	if !amulet.OutputMatches(craftedAmulet) {
+		t.Error("crafted amulet should match the output")
+	}
+	if amulet.OutputMatches(data.Item{ID: testAmuletID, Name: "Amulet", Quality: item.QualityMagic, Identified: true}) {
+		t.Error("magic amulet should not match the output")
+	}
+}
+
+func TestCubeRecipeInputRuleWithStats(t *testing.T) {
+	path := writeCubeRecipes(t, `
+recipes:
+  - name: Test
+    inputs:
+      - rule: "[type] == armor && [quality] == normal # [sockets] == 0"
+        quantity: 2
+`)
+	recipes, err := LoadCubeRecipes(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := recipes[0].Inputs[0]
+	if input.Quantity != 2 {
+		t.Errorf("expected quantity 2, got %d", input.Quantity)
+	}
+
+	armor := data.Item{ID: testQuiltedArmorID, Name: "QuiltedArmor", Quality: item.QualityNormal, Identified: true, Stats: stat.Stats{{ID: stat.NumSockets, Value: 0}}}
+	if !input.Matches(armor) {
+		t.Error("armor without sockets should match")
+	}
+	armor.Stats = stat.Stats{{ID: stat.NumSockets, Value: 2}}
+	if input.Matches(armor) {
+		t.Error("armor with sockets should not match")
+	}
+}
+
+func TestLoadCubeRecipesInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "Missing name", content: "recipes:\n  - inputs:\n      - name: TalRune\n", err: "name is required"},
+		{name: "Missing inputs", content: "recipes:\n  - name: Test\n", err: "at least one input"},
+		{name: "Empty input", content: "recipes:\n  - name: Test\n    inputs:\n      - quantity: 1\n", err: "name or rule is required"},
+		{name: "Negative quantity", content: "recipes:\n  - name: Test\n    inputs:\n      - name: TalRune\n        quantity: -1\n", err: "can not be negative"},
+		{name: "Unknown item", content: "recipes:\n  - name: Test\n    inputs:\n      - name: NotAnItem\n", err: "unknown item"},
+		{name: "Invalid rule", content: "recipes:\n  - name: Test\n    inputs:\n      - rule: \"[type] == == armor\"\n", err: "invalid rule"},
+		{name: "Rule without item properties", content: "recipes:\n  - name: Test\n    inputs:\n      - rule: \"# [sockets] == 0\"\n", err: "at least one item property"},
+		{name: "Too many items", content: "recipes:\n  - name: Test\n    inputs:\n      - name: TalRune\n        quantity: 13\n", err: "cells"},
+		{name: "Unknown purchase item", content: "recipes:\n  - name: Test\n    inputs:\n      - name: TalRune\n    purchase:\n      items: [ NotAnItem ]\n", err: "unknown purchase item"},
+		{name: "Unknown purchase quality", content: "recipes:\n  - name: Test\n    inputs:\n      - name: TalRune\n    purchase:\n      items: [ Amulet ]\n      quality: legendary\n", err: "unknown purchase quality"},
+		{name: "Duplicated name", content: "recipes:\n  - name: Test\n    inputs:\n      - name: TalRune\n  - name: Test\n    inputs:\n      - name: TalRune\n", err: "duplicated"},
+		{name: "Built-in recipe name", content: "recipes:\n  - name: Perfect Topaz\n    inputs:\n      - name: TalRune\n", err: "duplicated"},
+	}
+
+	for _, tt := range tests {
+		_, err := LoadCubeRecipes(writeCubeRecipes(t, tt.content))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type GameNameStrategy string
+
+const (
+	// GameNameCounter appends an increasing number to the game name template, the counter is kept between restarts
+	GameNameCounter GameNameStrategy = "counter"
+	// GameNameRandom uses random words, game name template is ignored
+	GameNameRandom GameNameStrategy = "random"
+	// GameNameDate appends the current date and time to the game name template
+	GameNameDate GameNameStrategy = "date"
+	// GameNameTemplate expands the expressions in the game name template: {char}, {n}, {date}, {time}, {word}, {rand}
+	GameNameTemplate GameNameStrategy = "template"
+)
+
+type GamePasswordStrategy string
+
+const (
+	GamePasswordFixed  GamePasswordStrategy = "fixed"
+	GamePasswordRandom GamePasswordStrategy = "random"
+	GamePasswordNone   GamePasswordStrategy = "none"
+)
+
+func gameCounterPath(supervisorName string) string {
+	return filepath.Join("config", supervisorName, "game_counter")
+}
+
+// LoadGameCounter returns the last game counter used by the supervisor, 0 if it was never saved
+func LoadGameCounter(supervisorName string) (int, error) {
+	d, err := os.ReadFile(gameCounterPath(supervisorName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("error reading game counter: %w", err)
+	}
+
+	counter, err := strconv.Atoi(strings.TrimSpace(string(d)))
+	if err != nil {
+		return 0, fmt.Errorf("error parsing game counter: %w", err)
+	}
+
+	return counter, nil
+}
+
+func SaveGameCounter(supervisorName string, counter int) error {
+	if err := os.WriteFile(gameCounterPath(supervisorName), []byte(strconv.Itoa(counter)), 0644); err != nil {
+		return fmt.Errorf("error writing game counter: %w", err)
+	}
+
+	return nil
+}
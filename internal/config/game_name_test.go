@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	return dir
+}
+
+func TestGameCounterRoundTrip(t *testing.T) {
+	dir := chdirTemp(t)
+	if err := os.MkdirAll(filepath.Join(dir, "config", "test"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	counter, err := LoadGameCounter("test")
+	if err != nil || counter != 0 {
+		t.Fatalf("LoadGameCounter() without file = %d, %v; want 0 and no error", counter, err)
+	}
+
+	if err = SaveGameCounter("test", 1234); err != nil {
+		t.Fatalf("SaveGameCounter() error: %v", err)
+	}
+	if counter, err = LoadGameCounter("test"); err != nil || counter != 1234 {
+		t.Errorf("LoadGameCounter() = %d, %v; want 1234", counter, err)
+	}
+
+	// Edited by hand
+	if err = os.WriteFile(gameCounterPath("test"), []byte(" 77\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if counter, err = LoadGameCounter("test"); err != nil || counter != 77 {
+		t.Errorf("LoadGameCounter() with spaces = %d, %v; want 77", counter, err)
+	}
+
+	if err = os.WriteFile(gameCounterPath("test"), []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadGameCounter("test"); err == nil {
+		t.Error("LoadGameCounter() with invalid content should fail")
+	}
+}
+
+func TestSaveGameCounterWithoutDirectory(t *testing.T) {
+	chdirTemp(t)
+
+	if err := SaveGameCounter("missing", 1); err == nil {
+		t.Error("SaveGameCounter() without the character config directory should fail")
+	}
+}
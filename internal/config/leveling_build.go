@@ -0,0 +1,211 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"gopkg.in/yaml.v3"
+)
+
+var levelingBuildStats = map[string]stat.ID{
+	"strength":  stat.Strength,
+	"dexterity": stat.Dexterity,
+	"vitality":  stat.Vitality,
+	"energy":    stat.Energy,
+}
+
+// LevelingBuild is a leveling template, it defines how stat and skill points are spent and which skills are bound,
+// combat is done by the class routine defined in Class.
+type LevelingBuild struct {
+	Name         string                `yaml:"name"`
+	Class        string                `yaml:"class"`
+	EndGameClass string                `yaml:"endGameClass"`
+	RespecLevel  int                   `yaml:"respecLevel"`
+	Stats        []LevelingBuildStats  `yaml:"stats"`
+	Skills       []LevelingBuildSkills `yaml:"skills"`
+	Bindings     []LevelingBuildKeys   `yaml:"bindings"`
+}
+
+type LevelingBuildStats struct {
+	FromLevel int                  `yaml:"fromLevel"`
+	Targets   LevelingBuildTargets `yaml:"targets"`
+}
+
+// LevelingBuildTargets keeps the stats in the same order they are written in the template, points are spent in that
+// order, so it can't be a map
+type LevelingBuildTargets []LevelingBuildTarget
+
+type LevelingBuildTarget struct {
+	Stat   string
+	Points int
+}
+
+func (t *LevelingBuildTargets) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: stat targets should be a map of stat: points", node.Line)
+	}
+
+	targets := make(LevelingBuildTargets, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var points int
+		if err := node.Content[i+1].Decode(&points); err != nil {
+			return fmt.Errorf("stat %s: %w", node.Content[i].Value, err)
+		}
+		targets = append(targets, LevelingBuildTarget{Stat: node.Content[i].Value, Points: points})
+	}
+	*t = targets
+
+	return nil
+}
+
+// StatPoints is the base value wanted for the stat
+type StatPoints struct {
+	Stat   stat.ID
+	Points int
+}
+
+type LevelingBuildSkills struct {
+	FromLevel int      `yaml:"fromLevel"`
+	Points    []string `yaml:"points"`
+}
+
+type LevelingBuildKeys struct {
+	FromLevel int      `yaml:"fromLevel"`
+	MainSkill string   `yaml:"mainSkill"`
+	Skills    []string `yaml:"skills"`
+}
+
+func LoadLevelingBuild(path string) (*LevelingBuild, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading leveling build: %w", err)
+	}
+	defer r.Close()
+
+	build := &LevelingBuild{}
+	if err = yaml.NewDecoder(r).Decode(build); err != nil {
+		return nil, fmt.Errorf("error reading leveling build %s: %w", path, err)
+	}
+
+	if err = build.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid leveling build %s: %w", path, err)
+	}
+
+	return build, nil
+}
+
+// Validate checks all the stats and skills in the template exist, so we don't find out in the middle of a game
+func (b *LevelingBuild) Validate() error {
+	if b.Class == "" {
+		return errors.New("class is required")
+	}
+
+	for _, s := range b.Stats {
+		for _, t := range s.Targets {
+			if _, found := levelingBuildStats[strings.ToLower(t.Stat)]; !found {
+				return fmt.Errorf("unknown stat %s", t.Stat)
+			}
+		}
+	}
+
+	skillNames := make([]string, 0)
+	for _, s := range b.Skills {
+		skillNames = append(skillNames, s.Points...)
+	}
+	for _, k := range b.Bindings {
+		if k.MainSkill != "" {
+			skillNames = append(skillNames, k.MainSkill)
+		}
+		skillNames = append(skillNames, k.Skills...)
+	}
+	for _, name := range skillNames {
+		if _, found := skillByName(name); !found {
+			return fmt.Errorf("unknown skill %s", name)
+		}
+	}
+
+	return nil
+}
+
+// StatTargets returns the stat targets for the last stats section reached by the given level, in the template order
+func (b *LevelingBuild) StatTargets(level int) []StatPoints {
+	targets := make([]StatPoints, 0)
+	for _, s := range b.Stats {
+		if s.FromLevel > level {
+			continue
+		}
+
+		targets = targets[:0]
+		for _, t := range s.Targets {
+			targets = append(targets, StatPoints{Stat: levelingBuildStats[strings.ToLower(t.Stat)], Points: t.Points})
+		}
+	}
+
+	return targets
+}
+
+// SkillPoints returns the skill points order for the last skills section reached by the given level
+func (b *LevelingBuild) SkillPoints(level int) []skill.ID {
+	points := make([]skill.ID, 0)
+	for _, s := range b.Skills {
+		if s.FromLevel > level {
+			continue
+		}
+
+		points = points[:0]
+		for _, name := range s.Points {
+			sk, _ := skillByName(name)
+			points = append(points, sk)
+		}
+	}
+
+	return points
+}
+
+// SkillBindings returns the main skill and the skills to bind, all the bindings sections reached by the level are
+// merged and the main skill is the one from the last section defining it
+func (b *LevelingBuild) SkillBindings(level int) (skill.ID, []skill.ID) {
+	mainSkill := skill.AttackSkill
+	bindings := make([]skill.ID, 0)
+	for _, k := range b.Bindings {
+		if k.FromLevel > level {
+			continue
+		}
+
+		if k.MainSkill != "" {
+			mainSkill, _ = skillByName(k.MainSkill)
+		}
+		for _, name := range k.Skills {
+			sk, _ := skillByName(name)
+			bindings = append(bindings, sk)
+		}
+	}
+
+	return mainSkill, bindings
+}
+
+// skillsByName is built once, sorted by ID, so the lookup is always the same even if two skills share the name
+var skillsByName = sync.OnceValue(func() map[string]skill.ID {
+	names := make(map[string]skill.ID, len(skill.SkillNames))
+	for _, id := range slices.Sorted(maps.Keys(skill.SkillNames)) {
+		name := strings.ToLower(skill.SkillNames[id])
+		if _, found := names[name]; !found {
+			names[name] = id
+		}
+	}
+
+	return names
+})
+
+func skillByName(name string) (skill.ID, bool) {
+	id, found := skillsByName()[strings.ToLower(name)]
+
+	return id, found
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"gopkg.in/yaml.v3"
+)
+
+const testLevelingBuild = `
+name: test
+class: sorceress_leveling
+stats:
+  - fromLevel: 1
+    targets:
+      vitality: 9999
+  - fromLevel: 20
+    targets:
+      strength: 60
+      Energy: 80
+      vitality: 9999
+skills:
+  - fromLevel: 1
+    points: [ FireBolt, firebolt, FrozenArmor ]
+  - fromLevel: 24
+    points: [ FireBall ]
+bindings:
+  - fromLevel: 1
+    skills: [ FrozenArmor ]
+  - fromLevel: 24
+    mainSkill: FireBall
+    skills: [ FireBall ]
+`
+
+func parseLevelingBuild(t *testing.T, content string) *LevelingBuild {
+	t.Helper()
+
+	build := &LevelingBuild{}
+	if err := yaml.Unmarshal([]byte(content), build); err != nil {
+		t.Fatalf("unexpected error parsing the build: %v", err)
+	}
+
+	return build
+}
+
+func TestLoadLevelingBuildTemplate(t *testing.T) {
+	build, err := LoadLevelingBuild("../../config/template/builds/sorceress_fire.yaml")
+	if err != nil {
+		t.Fatalf("template build should be valid: %v", err)
+	}
+
+	if build.Name != "sorceress_fire" || build.EndGameClass == "" {
+		t.Errorf("unexpected build %s, end game class %s", build.Name, build.EndGameClass)
+	}
+}
+
+func TestStatTargetsKeepTemplateOrder(t *testing.T) {
+	build := parseLevelingBuild(t, testLevelingBuild)
+	if err := build.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	tests := []struct {
+		level    int
+		expected []StatPoints
+	}{
+		{level: 1, expected: []StatPoints{{Stat: stat.Vitality, Points: 9999}}},
+		{level: 19, expected: []StatPoints{{Stat: stat.Vitality, Points: 9999}}},
+		{level: 20, expected: []StatPoints{
+			{Stat: stat.Strength, Points: 60},
+			{Stat: stat.Energy, Points: 80},
+			{Stat: stat.Vitality, Points: 9999},
+		}},
+	}
+
+	for _, tt := range tests {
+		// Repeated to make sure the order doesn't depend on map iteration
+		for range 10 {
+			if targets := build.StatTargets(tt.level); !slices.Equal(targets, tt.expected) {
+				t.Fatalf("level %d: expected %v, got %v", tt.level, tt.expected, targets)
+			}
+		}
+	}
+}
+
+func TestSkillPointsAndBindings(t *testing.T) {
+	build := parseLevelingBuild(t, testLevelingBuild)
+
+	if points := build.SkillPoints(10); !slices.Equal(points, []skill.ID{skill.FireBolt, skill.FireBolt, skill.FrozenArmor}) {
+		t.Errorf("unexpected skill points for level 10: %v", points)
+	}
+	if points := build.SkillPoints(30); !slices.Equal(points, []skill.ID{skill.FireBall}) {
+		t.Errorf("unexpected skill points for level 30: %v", points)
+	}
+
+	mainSkill, bindings := build.SkillBindings(10)
+	if mainSkill != skill.AttackSkill || !slices.Equal(bindings, []skill.ID{skill.FrozenArmor}) {
+		t.Errorf("unexpected bindings for level 10: %v %v", mainSkill, bindings)
+	}
+
+	mainSkill, bindings = build.SkillBindings(24)
+	if mainSkill != skill.FireBall || !slices.Equal(bindings, []skill.ID{skill.FrozenArmor, skill.FireBall}) {
+		t.Errorf("unexpected bindings for level 24: %v %v", mainSkill, bindings)
+	}
+}
+
+func TestValidateLevelingBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "missing class", content: "name: test", err: "class is required"},
+		{name: "unknown stat", content: "class: paladin\nstats: [ { fromLevel: 1, targets: { luck: 10 } } ]", err: "unknown stat luck"},
+		{name: "unknown skill point", content: "class: paladin\nskills: [ { fromLevel: 1, points: [ Smite, Fireworks ] } ]", err: "unknown skill Fireworks"},
+		{name: "unknown main skill", content: "class: paladin\nbindings: [ { fromLevel: 1, mainSkill: Fireworks } ]", err: "unknown skill Fireworks"},
+	}
+
+	for _, tt := range tests {
+		err := parseLevelingBuild(t, tt.content).Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected error %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestStatTargetsMustBeAMap(t *testing.T) {
+	build := &LevelingBuild{}
+	err := yaml.Unmarshal([]byte("class: paladin\nstats: [ { fromLevel: 1, targets: [ vitality ] } ]"), build)
+	if err == nil {
+		t.Errorf("expected error for stat targets not being a map")
+	}
+
+	err = yaml.Unmarshal([]byte("class: paladin\nstats: [ { fromLevel: 1, targets: { vitality: lots } } ]"), build)
+	if err == nil {
+		t.Errorf("expected error for non numeric stat points")
+	}
+}
+
+func TestSkillByName(t *testing.T) {
+	for _, name := range []string{"FireBall", "fireball", "FIREBALL"} {
+		if id, found := skillByName(name); !found || id != skill.FireBall {
+			t.Errorf("%s: expected FireBall, got %v (found %t)", name, id, found)
+		}
+	}
+
+	if _, found := skillByName("Fireworks"); found {
+		t.Errorf("unknown skill should not be found")
+	}
+}
@@ -31,6 +31,8 @@ const (
 	DrifterCavernRun    Run = "drifter_cavern"
 	SpiderCavernRun     Run = "spider_cavern"
 	EnduguRun           Run = "endugu"
+	ShoppingRun         Run = "shopping"
+	CompanionRun        Run = "companion"
 )
 
 var AvailableRuns = map[Run]interface{}{
@@ -62,4 +64,35 @@ var AvailableRuns = map[Run]interface{}{
 	DrifterCavernRun:    nil,
 	SpiderCavernRun:     nil,
 	EnduguRun:           nil,
+	ShoppingRun:         nil,
+}
+
+// RunOnError is what happens with the current game when a run fails
+type RunOnError string
+
+const (
+	// RunOnErrorEndGame finishes the game, this is the default behavior
+	RunOnErrorEndGame RunOnError = "end_game"
+	// RunOnErrorSkip continues with the next run in the same game
+	RunOnErrorSkip RunOnError = "skip"
+	// RunOnErrorRetry executes the run again in the same game, after all the retries the next run is executed
+	RunOnErrorRetry RunOnError = "retry"
+)
+
+type RunPolicy struct {
+	MaxDuration int        `yaml:"maxDuration"` // Seconds, 0 means no limit
+	Retries     int        `yaml:"retries"`
+	OnError     RunOnError `yaml:"onError"`
+}
+
+// RunPolicy returns the policy for the given run, runs without policy end the game on any error
+func (c *CharacterCfg) RunPolicy(run string) RunPolicy {
+	policy := c.Game.RunPolicies[Run(run)]
+	switch policy.OnError {
+	case RunOnErrorSkip, RunOnErrorRetry:
+	default:
+		policy.OnError = RunOnErrorEndGame
+	}
+
+	return policy
 }
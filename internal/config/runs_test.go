@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestRunPolicy(t *testing.T) {
+	cfg := &CharacterCfg{}
+	cfg.Game.RunPolicies = map[Run]RunPolicy{
+		PitRun:      {MaxDuration: 300, Retries: 2, OnError: RunOnErrorRetry},
+		CountessRun: {OnError: RunOnErrorSkip},
+		AndarielRun: {MaxDuration: 120},
+		MephistoRun: {OnError: "unknown"},
+	}
+
+	tests := []struct {
+		run      Run
+		expected RunPolicy
+	}{
+		{run: PitRun, expected: RunPolicy{MaxDuration: 300, Retries: 2, OnError: RunOnErrorRetry}},
+		{run: CountessRun, expected: RunPolicy{OnError: RunOnErrorSkip}},
+		{run: AndarielRun, expected: RunPolicy{MaxDuration: 120, OnError: RunOnErrorEndGame}},
+		{run: MephistoRun, expected: RunPolicy{OnError: RunOnErrorEndGame}},
+		{run: BaalRun, expected: RunPolicy{OnError: RunOnErrorEndGame}},
+	}
+
+	for _, tt := range tests {
+		if got := cfg.RunPolicy(string(tt.run)); got != tt.expected {
+			t.Errorf("RunPolicy(%s) = %+v, want %+v", tt.run, got, tt.expected)
+		}
+	}
+}
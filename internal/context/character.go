@@ -28,12 +28,75 @@ type Character interface {
 	) error
 }
 
+// AttackSkill is a skill used to damage monsters, Resists are the immunities needed to block all its damage.
+type AttackSkill struct {
+	Skill   skill.ID
+	Resists []stat.Resist
+}
+
+// CanDamage returns false only if the monster is immune to all the damage types of the skill
+func (s AttackSkill) CanDamage(m data.Monster) bool {
+	if len(s.Resists) == 0 {
+		return true
+	}
+
+	for _, r := range s.Resists {
+		if !m.IsImmune(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MultiElementCharacter is implemented by characters able to switch the attack skill depending on the monster
+// immunities, monsters will be skipped only if none of the skills can damage them.
+type MultiElementCharacter interface {
+	Character
+	// AttackSkills Skills are returned ordered by preference, first one able to damage the monster will be used.
+	AttackSkills() []AttackSkill
+}
+
+// StatTarget is the base value (without item bonuses) we want for the stat
+type StatTarget struct {
+	Stat   stat.ID
+	Points int
+}
+
 type LevelingCharacter interface {
 	Character
-	// StatPoints Stats will be assigned in the order they are returned by this function.
-	StatPoints() map[stat.ID]int
+	// StatPoints Stats will be assigned in the order they are returned by this function, each one until reaching its
+	// target, use a big number to assign the remaining points.
+	StatPoints() []StatTarget
 	SkillPoints() []skill.ID
 	SkillsToBind() (skill.ID, []skill.ID)
 	ShouldResetSkills() bool
 	KillAncients() error
 }
+
+// DamageResistsCharacter is implemented by characters without AttackSkills declaring the immunities needed to block all
+// their damage, it's used to attack those immune monsters last.
+type DamageResistsCharacter interface {
+	Character
+	DamageResists() []stat.Resist
+}
+
+// IsImmuneToCharacter returns true if the current character can not damage the monster with any of its skills.
+// Characters not declaring AttackSkills or DamageResists are assumed to damage everything.
+func (ctx *Context) IsImmuneToCharacter(m data.Monster) bool {
+	if mec, ok := ctx.Char.(MultiElementCharacter); ok && len(mec.AttackSkills()) > 0 {
+		for _, s := range mec.AttackSkills() {
+			if s.CanDamage(m) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if drc, ok := ctx.Char.(DamageResistsCharacter); ok {
+		return !AttackSkill{Resists: drc.DamageResists()}.CanDamage(m)
+	}
+
+	return false
+}
@@ -1,6 +1,7 @@
 package context
 
 import (
+	"errors"
 	"log/slog"
 	"runtime"
 	"strconv"
@@ -20,6 +21,9 @@ import (
 var mu sync.Mutex
 var botContexts = make(map[uint64]*Status)
 
+// ErrRunTimeout is raised (as panic) from the run routine once the run max duration is reached
+var ErrRunTimeout = errors.New("run max duration reached")
+
 type Priority int
 
 const (
@@ -68,6 +72,8 @@ type CurrentGameHelper struct {
 		ExpectedArea area.ID
 	}
 	PickupItems bool
+	// RunDeadline interrupts the current run once reached, zero means no limit
+	RunDeadline time.Time
 }
 
 func NewContext(name string) *Status {
@@ -155,6 +161,10 @@ func (s *Status) PauseIfNotPriority() {
 		time.Sleep(time.Millisecond * 5)
 	}
 
+	if s.Priority == PriorityNormal && !s.CurrentGame.RunDeadline.IsZero() && time.Now().After(s.CurrentGame.RunDeadline) {
+		panic(ErrRunTimeout)
+	}
+
 	for s.Priority != s.ExecutionPriority {
 		if s.ExecutionPriority == PriorityStop {
 			panic("Bot is stopped")
@@ -1,7 +1,12 @@
 package event
 
 import (
+	"time"
+
 	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
 )
 
 const (
@@ -10,6 +15,7 @@ const (
 	FinishedChicken     FinishReason = "chicken"
 	FinishedMercChicken FinishReason = "merc chicken"
 	FinishedError       FinishReason = "error"
+	FinishedTimeout     FinishReason = "timeout"
 
 	InteractionTypeEntrance InteractionType = "entrance"
 	InteractionTypeNPC      InteractionType = "npc"
@@ -60,13 +66,15 @@ type RunFinishedEvent struct {
 	BaseEvent
 	RunName string
 	Reason  FinishReason
+	Attempt int
 }
 
-func RunFinished(be BaseEvent, runName string, reason FinishReason) RunFinishedEvent {
+func RunFinished(be BaseEvent, runName string, reason FinishReason, attempt int) RunFinishedEvent {
 	return RunFinishedEvent{
 		BaseEvent: be,
 		RunName:   runName,
 		Reason:    reason,
+		Attempt:   attempt,
 	}
 }
 
@@ -85,6 +93,8 @@ func ItemStashed(be BaseEvent, drop data.Drop) ItemStashedEvent {
 type RunStartedEvent struct {
 	BaseEvent
 	RunName string
+	// Attempt starts at 1, it's increased every time the run is retried in the same game
+	Attempt int
 }
 
 type ItemBlackListedEvent struct {
@@ -99,10 +109,140 @@ func ItemBlackListed(be BaseEvent, drop data.Drop) ItemBlackListedEvent {
 	}
 }
 
-func RunStarted(be BaseEvent, runName string) RunStartedEvent {
+type ItemEquippedEvent struct {
+	BaseEvent
+	Item data.Item
+	Merc bool
+}
+
+func ItemEquipped(be BaseEvent, itm data.Item, merc bool) ItemEquippedEvent {
+	return ItemEquippedEvent{
+		BaseEvent: be,
+		Item:      itm,
+		Merc:      merc,
+	}
+}
+
+type ItemPurchasedEvent struct {
+	BaseEvent
+	Item   data.Drop
+	Vendor npc.ID
+}
+
+func ItemPurchased(be BaseEvent, drop data.Drop, vendor npc.ID) ItemPurchasedEvent {
+	return ItemPurchasedEvent{
+		BaseEvent: be,
+		Item:      drop,
+		Vendor:    vendor,
+	}
+}
+
+type ItemGambledEvent struct {
+	BaseEvent
+	Item  data.Item
+	Price int
+	Kept  bool
+}
+
+func ItemGambled(be BaseEvent, itm data.Item, price int, kept bool) ItemGambledEvent {
+	return ItemGambledEvent{
+		BaseEvent: be,
+		Item:      itm,
+		Price:     price,
+		Kept:      kept,
+	}
+}
+
+type GamblingFinishedEvent struct {
+	BaseEvent
+	Spent       int
+	ItemsBought int
+	ItemsKept   int
+	Qualities   map[string]int
+	Reason      string
+}
+
+func GamblingFinished(be BaseEvent, spent, itemsBought, itemsKept int, qualities map[string]int, reason string) GamblingFinishedEvent {
+	return GamblingFinishedEvent{
+		BaseEvent:   be,
+		Spent:       spent,
+		ItemsBought: itemsBought,
+		ItemsKept:   itemsKept,
+		Qualities:   qualities,
+		Reason:      reason,
+	}
+}
+
+type CubeRecipeTransmutedEvent struct {
+	BaseEvent
+	Recipe         string
+	InputsConsumed int
+	Outputs        []data.Item
+	Keepers        int
+}
+
+func CubeRecipeTransmuted(be BaseEvent, recipe string, inputsConsumed int, outputs []data.Item, keepers int) CubeRecipeTransmutedEvent {
+	return CubeRecipeTransmutedEvent{
+		BaseEvent:      be,
+		Recipe:         recipe,
+		InputsConsumed: inputsConsumed,
+		Outputs:        outputs,
+		Keepers:        keepers,
+	}
+}
+
+func RunStarted(be BaseEvent, runName string, attempt int) RunStartedEvent {
 	return RunStartedEvent{
 		BaseEvent: be,
 		RunName:   runName,
+		Attempt:   attempt,
+	}
+}
+
+type OnlineFailureEvent struct {
+	BaseEvent
+	Reason        string
+	Failures      int
+	Backoff       time.Duration
+	RestartClient bool
+}
+
+func OnlineFailure(be BaseEvent, reason string, failures int, backoff time.Duration, restartClient bool) OnlineFailureEvent {
+	return OnlineFailureEvent{
+		BaseEvent:     be,
+		Reason:        reason,
+		Failures:      failures,
+		Backoff:       backoff,
+		RestartClient: restartClient,
+	}
+}
+
+// SupervisorCrashLoopEvent is sent when the client crashed too many times in a row and the supervisor won't be restarted
+type SupervisorCrashLoopEvent struct {
+	BaseEvent
+	ConsecutiveCrashes int
+	SummaryPath        string
+}
+
+func SupervisorCrashLoop(be BaseEvent, consecutiveCrashes int, summaryPath string) SupervisorCrashLoopEvent {
+	return SupervisorCrashLoopEvent{
+		BaseEvent:          be,
+		ConsecutiveCrashes: consecutiveCrashes,
+		SummaryPath:        summaryPath,
+	}
+}
+
+type StuckDetectedEvent struct {
+	BaseEvent
+	Recovery   string
+	StalledFor time.Duration
+}
+
+func StuckDetected(be BaseEvent, recovery string, stalledFor time.Duration) StuckDetectedEvent {
+	return StuckDetectedEvent{
+		BaseEvent:  be,
+		Recovery:   recovery,
+		StalledFor: stalledFor,
 	}
 }
 
@@ -126,6 +266,18 @@ func CompanionRequestedTP(be BaseEvent) CompanionRequestedTPEvent {
 	return CompanionRequestedTPEvent{BaseEvent: be}
 }
 
+type CompanionPortalOpenedEvent struct {
+	BaseEvent
+	Area area.ID
+}
+
+func CompanionPortalOpened(be BaseEvent, a area.ID) CompanionPortalOpenedEvent {
+	return CompanionPortalOpenedEvent{
+		BaseEvent: be,
+		Area:      a,
+	}
+}
+
 type InteractedToEvent struct {
 	BaseEvent
 	ID              int
@@ -151,3 +303,31 @@ func GamePaused(be BaseEvent, paused bool) GamePausedEvent {
 		Paused:    paused,
 	}
 }
+
+type StatPointsAllocatedEvent struct {
+	BaseEvent
+	Level  int
+	Points map[stat.ID]int
+}
+
+func StatPointsAllocated(be BaseEvent, level int, points map[stat.ID]int) StatPointsAllocatedEvent {
+	return StatPointsAllocatedEvent{
+		BaseEvent: be,
+		Level:     level,
+		Points:    points,
+	}
+}
+
+type LevelingFinishedEvent struct {
+	BaseEvent
+	Class string
+	Runs  []string
+}
+
+func LevelingFinished(be BaseEvent, class string, runs []string) LevelingFinishedEvent {
+	return LevelingFinishedEvent{
+		BaseEvent: be,
+		Class:     class,
+		Runs:      runs,
+	}
+}
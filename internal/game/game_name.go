@@ -0,0 +1,136 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+// D2R doesn't allow longer game names
+const maxGameNameLength = 15
+
+var gameNameWords = []string{
+	"ash", "bear", "bone", "claw", "crow", "dawn", "doom", "dusk", "fang", "fire", "frost", "gale", "hawk", "iron",
+	"moon", "oak", "rune", "sky", "soul", "star", "stone", "storm", "thorn", "tomb", "void", "wolf", "wyrm",
+}
+
+const randomPasswordChars = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// Expressions allowed in the game name template
+var gameNameExpression = regexp.MustCompile(`\{(char|n|date|time|word|rand)\}`)
+
+type gameNameGenerator struct {
+	supervisorName string
+	counter        int
+	loaded         bool
+	last           string
+}
+
+// next returns the name and password for a new game, the counter is increased and saved every time it's called
+func (g *gameNameGenerator) next(cfg *config.CharacterCfg) (string, string) {
+	if !g.loaded {
+		// If it can not be loaded we start from 0, collisions will be handled by creating the game with the next name
+		g.counter, _ = config.LoadGameCounter(g.supervisorName)
+		g.loaded = true
+	}
+	g.counter++
+	_ = config.SaveGameCounter(g.supervisorName, g.counter)
+
+	template := cfg.Companion.GameNameTemplate
+	switch cfg.Companion.GameNameStrategy {
+	case config.GameNameRandom:
+		template = "{word}{word}{rand}"
+	case config.GameNameDate:
+		template += "{date}{time}"
+	case config.GameNameTemplate:
+		// Template without expressions would always lead to the same name
+		if !strings.Contains(template, "{") {
+			template += "{n}"
+		}
+	default:
+		template += "{n}"
+	}
+
+	name := g.expand(template, cfg, time.Now())
+	// Retried in the same second after a collision, or the template doesn't change between games
+	if name == g.last {
+		name = g.expand(template+"{n}", cfg, time.Now())
+	}
+	g.last = name
+
+	return name, gamePassword(cfg)
+}
+
+// expand replaces the template expressions. Names longer than the D2R limit are shortened keeping the variable part
+// at the end, so names are still different, and the start of the template, so the {char} prefix is kept.
+func (g *gameNameGenerator) expand(template string, cfg *config.CharacterCfg, now time.Time) string {
+	head := ""
+	tail := ""
+	last := 0
+	for _, match := range gameNameExpression.FindAllStringSubmatchIndex(template, -1) {
+		if match[0] > last {
+			head += tail + template[last:match[0]]
+			tail = ""
+		}
+		last = match[1]
+
+		switch expression := template[match[2]:match[3]]; expression {
+		case "char":
+			head += tail + cfg.CharacterName
+			tail = ""
+		default:
+			tail += g.expression(expression, now)
+		}
+	}
+	if last < len(template) {
+		head += tail + template[last:]
+		tail = ""
+	}
+
+	if len(head)+len(tail) <= maxGameNameLength {
+		return head + tail
+	}
+	if len(tail) >= maxGameNameLength {
+		return tail[len(tail)-maxGameNameLength:]
+	}
+
+	return head[:maxGameNameLength-len(tail)] + tail
+}
+
+// expression returns the value of a variable expression, every expression gets a different value
+func (g *gameNameGenerator) expression(expression string, now time.Time) string {
+	switch expression {
+	case "n":
+		return strconv.Itoa(g.counter)
+	case "date":
+		return now.Format("0102")
+	case "time":
+		return now.Format("150405")
+	case "word":
+		return gameNameWords[rand.Intn(len(gameNameWords))]
+	case "rand":
+		return fmt.Sprintf("%02d", rand.Intn(100))
+	}
+
+	return ""
+}
+
+func gamePassword(cfg *config.CharacterCfg) string {
+	switch cfg.Companion.GamePasswordStrategy {
+	case config.GamePasswordNone:
+		return ""
+	case config.GamePasswordRandom:
+		password := make([]byte, 4)
+		for i := range password {
+			password[i] = randomPasswordChars[rand.Intn(len(randomPasswordChars))]
+		}
+		return string(password)
+	default:
+		return cfg.Companion.GamePassword
+	}
+}
@@ -0,0 +1,124 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+func TestGameNameExpand(t *testing.T) {
+	now := time.Date(2024, 3, 7, 9, 5, 2, 0, time.UTC)
+	cfg := &config.CharacterCfg{}
+	cfg.CharacterName = "Sorc"
+
+	tests := []struct {
+		name     string
+		template string
+		counter  int
+		expected string
+	}{
+		{name: "Counter", template: "game-{n}", counter: 12, expected: "game-12"},
+		{name: "Character and counter", template: "{char}-{n}", counter: 3, expected: "Sorc-3"},
+		{name: "Date and time with seconds", template: "g{date}{time}", expected: "g0307090502"},
+		{name: "Text after the variable part", template: "{n}-run", counter: 7, expected: "7-run"},
+		{name: "Unknown expressions are kept", template: "{foo}{n}", counter: 1, expected: "{foo}1"},
+		{name: "Long template keeps character and counter", template: "{char}-very-long-name-{n}", counter: 12345, expected: "Sorc-very-12345"},
+		{name: "Long date keeps the start", template: "baalgames-{date}{time}", expected: "baalg0307090502"},
+		{name: "Variable part longer than the limit", template: "x{date}{time}{n}", counter: 123456, expected: "307090502123456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &gameNameGenerator{counter: tt.counter}
+			name := g.expand(tt.template, cfg, now)
+			if name != tt.expected {
+				t.Errorf("expand(%q) = %q, want %q", tt.template, name, tt.expected)
+			}
+			if len(name) > maxGameNameLength {
+				t.Errorf("expand(%q) = %q is longer than %d", tt.template, name, maxGameNameLength)
+			}
+		})
+	}
+}
+
+func TestGameNameExpandRandom(t *testing.T) {
+	g := &gameNameGenerator{}
+	name := g.expand("{word}-{rand}", &config.CharacterCfg{}, time.Now())
+
+	word, number, found := strings.Cut(name, "-")
+	if !found || len(number) != 2 {
+		t.Fatalf("Expected word and two digits number, got %q", name)
+	}
+	if !strings.Contains(strings.Join(gameNameWords, ","), word) {
+		t.Errorf("Unexpected word %q", word)
+	}
+}
+
+func TestGamePassword(t *testing.T) {
+	cfg := &config.CharacterCfg{}
+	cfg.Companion.GamePassword = "xxx"
+
+	cfg.Companion.GamePasswordStrategy = config.GamePasswordFixed
+	if password := gamePassword(cfg); password != "xxx" {
+		t.Errorf("Fixed password = %q, want xxx", password)
+	}
+
+	cfg.Companion.GamePasswordStrategy = ""
+	if password := gamePassword(cfg); password != "xxx" {
+		t.Errorf("Default password = %q, want the fixed one", password)
+	}
+
+	cfg.Companion.GamePasswordStrategy = config.GamePasswordNone
+	if password := gamePassword(cfg); password != "" {
+		t.Errorf("None password = %q, want empty", password)
+	}
+
+	cfg.Companion.GamePasswordStrategy = config.GamePasswordRandom
+	password := gamePassword(cfg)
+	if len(password) != 4 || strings.Trim(password, randomPasswordChars) != "" {
+		t.Errorf("Random password = %q, want 4 characters from %q", password, randomPasswordChars)
+	}
+}
+
+func TestGameNameNext(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = os.MkdirAll(filepath.Join(dir, "config", "test"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	if err = config.SaveGameCounter("test", 41); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.CharacterCfg{}
+	cfg.Companion.GameNameTemplate = "game-"
+	cfg.Companion.GameNameStrategy = config.GameNameCounter
+
+	g := &gameNameGenerator{supervisorName: "test"}
+	if name, _ := g.next(cfg); name != "game-42" {
+		t.Errorf("First game = %q, want the saved counter increased", name)
+	}
+	if counter, _ := config.LoadGameCounter("test"); counter != 42 {
+		t.Errorf("Saved counter = %d, want 42", counter)
+	}
+
+	// Collision retries in the same second should not lead to the same name
+	cfg.Companion.GameNameStrategy = config.GameNameDate
+	first, _ := g.next(cfg)
+	second, _ := g.next(cfg)
+	if first == second {
+		t.Errorf("Expected different names for consecutive games, got %q twice", first)
+	}
+}
@@ -1,6 +1,9 @@
 package game
 
-import "github.com/hectorgimenez/d2go/pkg/data"
+import (
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/utils"
+)
 
 const (
 	CollisionTypeNonWalkable CollisionType = iota
@@ -18,6 +21,8 @@ type Grid struct {
 	Width         int
 	Height        int
 	CollisionGrid [][]CollisionType
+	// ThreatGrid holds the extra cost of walking through each tile, it's nil until a threat is added
+	ThreatGrid [][]int
 }
 
 func NewGrid(rawCollisionGrid [][]CollisionType, offsetX, offsetY int) *Grid {
@@ -65,6 +70,36 @@ func (g *Grid) IsWalkable(p data.Position) bool {
 	return p.X >= 0 && p.X < g.Width && p.Y >= 0 && p.Y < g.Height && g.CollisionGrid[p.Y][p.X] != CollisionTypeNonWalkable
 }
 
+// AddThreat increases the cost of the tiles around the given relative position, the cost decreases linearly with the
+// distance, being the full weight at the center and close to 0 at the edge of the radius.
+func (g *Grid) AddThreat(p data.Position, radius, weight int) {
+	if g.ThreatGrid == nil {
+		g.ThreatGrid = make([][]int, g.Height)
+		for y := range g.ThreatGrid {
+			g.ThreatGrid[y] = make([]int, g.Width)
+		}
+	}
+
+	for y := max(p.Y-radius, 0); y <= min(p.Y+radius, g.Height-1); y++ {
+		for x := max(p.X-radius, 0); x <= min(p.X+radius, g.Width-1); x++ {
+			distance := utils.DistanceFromPoint(p, data.Position{X: x, Y: y})
+			if distance > radius {
+				continue
+			}
+			g.ThreatGrid[y][x] += weight * (radius - distance + 1) / (radius + 1)
+		}
+	}
+}
+
+// ThreatCost returns the extra cost of walking through the given relative position
+func (g *Grid) ThreatCost(p data.Position) int {
+	if g.ThreatGrid == nil || p.X < 0 || p.X >= g.Width || p.Y < 0 || p.Y >= g.Height {
+		return 0
+	}
+
+	return g.ThreatGrid[p.Y][p.X]
+}
+
 func (g *Grid) Copy() *Grid {
 	cg := make([][]CollisionType, g.Height)
 	for y := 0; y < g.Height; y++ {
@@ -72,11 +107,21 @@ func (g *Grid) Copy() *Grid {
 		copy(cg[y], g.CollisionGrid[y])
 	}
 
+	var tg [][]int
+	if g.ThreatGrid != nil {
+		tg = make([][]int, g.Height)
+		for y := 0; y < g.Height; y++ {
+			tg[y] = make([]int, g.Width)
+			copy(tg[y], g.ThreatGrid[y])
+		}
+	}
+
 	return &Grid{
 		OffsetX:       g.OffsetX,
 		OffsetY:       g.OffsetY,
 		Width:         g.Width,
 		Height:        g.Height,
 		CollisionGrid: cg,
+		ThreatGrid:    tg,
 	}
 }
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+)
+
+func walkableGrid(width, height int) *Grid {
+	cg := make([][]CollisionType, height)
+	for y := range cg {
+		cg[y] = make([]CollisionType, width)
+		for x := range cg[y] {
+			cg[y][x] = CollisionTypeWalkable
+		}
+	}
+
+	return NewGrid(cg, 0, 0)
+}
+
+func TestAddThreat(t *testing.T) {
+	g := walkableGrid(10, 10)
+	g.AddThreat(data.Position{X: 5, Y: 5}, 3, 40)
+
+	tests := []struct {
+		pos      data.Position
+		expected int
+	}{
+		{pos: data.Position{X: 5, Y: 5}, expected: 40},
+		{pos: data.Position{X: 6, Y: 5}, expected: 30},
+		{pos: data.Position{X: 5, Y: 3}, expected: 20},
+		{pos: data.Position{X: 8, Y: 5}, expected: 10},
+		{pos: data.Position{X: 9, Y: 5}, expected: 0},
+		{pos: data.Position{X: 0, Y: 0}, expected: 0},
+	}
+	for _, tt := range tests {
+		if cost := g.ThreatCost(tt.pos); cost != tt.expected {
+			t.Errorf("ThreatCost(%v) = %d, expected %d", tt.pos, cost, tt.expected)
+		}
+	}
+}
+
+func TestAddThreatAccumulatesAndClamps(t *testing.T) {
+	g := walkableGrid(10, 10)
+
+	// Close to the edges, tiles outside the grid are ignored
+	g.AddThreat(data.Position{X: 0, Y: 0}, 3, 40)
+	g.AddThreat(data.Position{X: 1, Y: 0}, 3, 40)
+
+	if cost := g.ThreatCost(data.Position{X: 0, Y: 0}); cost != 70 {
+		t.Errorf("expected accumulated cost 70, got %d", cost)
+	}
+}
+
+func TestThreatCostWithoutThreats(t *testing.T) {
+	g := walkableGrid(10, 10)
+	if g.ThreatGrid != nil {
+		t.Fatalf("threat grid should not be allocated until a threat is added")
+	}
+	if cost := g.ThreatCost(data.Position{X: 5, Y: 5}); cost != 0 {
+		t.Errorf("expected 0 cost, got %d", cost)
+	}
+
+	g.AddThreat(data.Position{X: 5, Y: 5}, 2, 10)
+	if cost := g.ThreatCost(data.Position{X: -1, Y: 5}); cost != 0 {
+		t.Errorf("expected 0 cost outside the grid, got %d", cost)
+	}
+	if cost := g.ThreatCost(data.Position{X: 5, Y: 10}); cost != 0 {
+		t.Errorf("expected 0 cost outside the grid, got %d", cost)
+	}
+}
+
+func TestCopyKeepsThreats(t *testing.T) {
+	g := walkableGrid(10, 10)
+	g.AddThreat(data.Position{X: 5, Y: 5}, 2, 10)
+
+	c := g.Copy()
+	c.AddThreat(data.Position{X: 5, Y: 5}, 2, 10)
+
+	if cost := g.ThreatCost(data.Position{X: 5, Y: 5}); cost != 10 {
+		t.Errorf("original grid modified by the copy, cost %d", cost)
+	}
+	if cost := c.ThreatCost(data.Position{X: 5, Y: 5}); cost != 20 {
+		t.Errorf("expected copy cost 20, got %d", cost)
+	}
+}
@@ -0,0 +1,195 @@
+package game
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/utils"
+)
+
+// Join game tab list, it has the same layout as the character list, a container with a ListItem child per game.
+// Path has been taken from the character list layout and it's not verified against a live client yet, that's why
+// joining games is flagged as experimental. If the client uses a different one the list is searched by its name and
+// LobbyGames logs the panels found, so the path can be fixed.
+var lobbyGameListPath = []string{"LobbyBackgroundPanel", "JoinGamePanel", "GameList", "View", "Container"}
+
+type LobbyGame struct {
+	Name       string
+	Players    int
+	MaxPlayers int
+	Difficulty difficulty.Difficulty
+	// LevelRequirement is 0 when the game doesn't have level restrictions
+	LevelRequirement int
+}
+
+// LobbyGames opens the join game tab and reads the game list, lobby should be already open
+func (gm *Manager) LobbyGames() []LobbyGame {
+	// Click "Join game" tab, the list is refreshed every time the tab is opened
+	gm.hid.Click(LeftButton, 977, 54)
+	utils.Sleep(1500)
+
+	return gm.gr.LobbyGames()
+}
+
+func (gd *MemoryReader) LobbyGames() []LobbyGame {
+	container := gd.GetPanel(lobbyGameListPath...)
+	if container.PanelName == "" {
+		panels := gd.ReadAllPanels()
+		found := false
+		if container, found = findLobbyGameList(panels); !found {
+			gd.logger.Warn("Join game list not found", slog.Any("panels", slices.Sorted(maps.Keys(panels))))
+			return []LobbyGame{}
+		}
+		gd.logger.Debug("Join game list found outside the expected path", slog.String("parent", container.PanelParent))
+	}
+
+	return parseLobbyGames(container)
+}
+
+// findLobbyGameList looks for the game list container anywhere in the panel tree
+func findLobbyGameList(panels map[string]data.Panel) (data.Panel, bool) {
+	for _, name := range slices.Sorted(maps.Keys(panels)) {
+		p := panels[name]
+		if p.PanelName == "GameList" {
+			for _, child := range []string{"View", "Container"} {
+				if next, found := p.PanelChildren[child]; found {
+					p = next
+				}
+			}
+			return p, true
+		}
+
+		if found, ok := findLobbyGameList(p.PanelChildren); ok {
+			return found, true
+		}
+	}
+
+	return data.Panel{}, false
+}
+
+// parseLobbyGames reads the game rows, a ListItem child per game with a child panel per column
+func parseLobbyGames(container data.Panel) []LobbyGame {
+	if container.NumChildren == 0 {
+		return []LobbyGame{}
+	}
+
+	games := make([]LobbyGame, 0, container.NumChildren)
+	for i := 0; i < container.NumChildren; i++ {
+		row, found := container.PanelChildren[fmt.Sprintf("ListItem%d", i)]
+		if !found {
+			continue
+		}
+
+		g := LobbyGame{Name: strings.TrimSpace(row.PanelChildren["Name"].ExtraText3)}
+		if g.Name == "" {
+			continue
+		}
+
+		// Players column is shown as "3/8"
+		fmt.Sscanf(row.PanelChildren["Players"].ExtraText3, "%d/%d", &g.Players, &g.MaxPlayers)
+		g.LevelRequirement, _ = strconv.Atoi(strings.TrimSpace(row.PanelChildren["Level"].ExtraText3))
+
+		diff := strings.ToLower(row.PanelChildren["Difficulty"].ExtraText3)
+		for _, d := range []difficulty.Difficulty{difficulty.Normal, difficulty.Nightmare, difficulty.Hell} {
+			if strings.Contains(diff, string(d)) {
+				g.Difficulty = d
+			}
+		}
+
+		games = append(games, g)
+	}
+
+	return games
+}
+
+// GameBlacklist keeps the games recently joined, so we don't join them again
+type GameBlacklist struct {
+	games map[string]time.Time
+	// ttl is read every time, so config changes are applied to the games already in the list
+	ttl func() time.Duration
+	now func() time.Time
+}
+
+func NewGameBlacklist(ttl func() time.Duration) *GameBlacklist {
+	return &GameBlacklist{
+		games: make(map[string]time.Time),
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+func (b *GameBlacklist) Add(gameName string) {
+	b.games[strings.ToLower(gameName)] = b.now()
+}
+
+func (b *GameBlacklist) Contains(gameName string) bool {
+	ttl := b.ttl()
+	for name, joinedAt := range b.games {
+		if b.now().Sub(joinedAt) > ttl {
+			delete(b.games, name)
+		}
+	}
+
+	_, found := b.games[strings.ToLower(gameName)]
+
+	return found
+}
+
+// FindGameToJoin returns the first game matching the join filters that was not joined recently
+func FindGameToJoin(games []LobbyGame, cfg *config.CharacterCfg, blacklist *GameBlacklist) (LobbyGame, bool, error) {
+	filters := cfg.Game.JoinGames
+
+	var namePattern *regexp.Regexp
+	if filters.NamePattern != "" {
+		var err error
+		namePattern, err = regexp.Compile("(?i)" + filters.NamePattern)
+		if err != nil {
+			return LobbyGame{}, false, fmt.Errorf("invalid game name pattern %q: %w", filters.NamePattern, err)
+		}
+	}
+
+	for _, g := range games {
+		if blacklist.Contains(g.Name) {
+			continue
+		}
+
+		if namePattern != nil && !namePattern.MatchString(g.Name) {
+			continue
+		}
+
+		// Difficulty and level requirement are not always shown, we only skip the game if we know it doesn't match
+		if filters.Difficulty != "" && g.Difficulty != "" && g.Difficulty != filters.Difficulty {
+			continue
+		}
+
+		if filters.MaxLevelRequirement > 0 && g.LevelRequirement > filters.MaxLevelRequirement {
+			continue
+		}
+
+		if g.Players < filters.MinPlayers {
+			continue
+		}
+
+		if filters.MaxPlayers > 0 && g.Players > filters.MaxPlayers {
+			continue
+		}
+
+		// Full games can not be joined
+		if g.MaxPlayers > 0 && g.Players >= g.MaxPlayers {
+			continue
+		}
+
+		return g, true, nil
+	}
+
+	return LobbyGame{}, false, nil
+}
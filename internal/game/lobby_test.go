@@ -0,0 +1,191 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+func lobbyRow(name, players, level, diff string) data.Panel {
+	return data.Panel{
+		PanelName: "ListItem",
+		PanelChildren: map[string]data.Panel{
+			"Name":       {ExtraText3: name},
+			"Players":    {ExtraText3: players},
+			"Level":      {ExtraText3: level},
+			"Difficulty": {ExtraText3: diff},
+		},
+	}
+}
+
+func lobbyContainer(rows ...data.Panel) data.Panel {
+	children := make(map[string]data.Panel, len(rows))
+	for i, row := range rows {
+		children[fmt.Sprintf("ListItem%d", i)] = row
+	}
+
+	return data.Panel{PanelName: "Container", NumChildren: len(rows), PanelChildren: children}
+}
+
+func TestParseLobbyGames(t *testing.T) {
+	games := parseLobbyGames(lobbyContainer(
+		lobbyRow(" baal-12 ", "3/8", "80", "Hell"),
+		lobbyRow("", "1/8", "", "Normal"),
+		lobbyRow("cows", "8/8", "", "Nightmare"),
+		lobbyRow("chaos", "", "", ""),
+	))
+
+	expected := []LobbyGame{
+		{Name: "baal-12", Players: 3, MaxPlayers: 8, Difficulty: difficulty.Hell, LevelRequirement: 80},
+		{Name: "cows", Players: 8, MaxPlayers: 8, Difficulty: difficulty.Nightmare},
+		{Name: "chaos"},
+	}
+	if len(games) != len(expected) {
+		t.Fatalf("Expected %d games, got %d: %+v", len(expected), len(games), games)
+	}
+	for i := range expected {
+		if games[i] != expected[i] {
+			t.Errorf("Game %d: expected %+v, got %+v", i, expected[i], games[i])
+		}
+	}
+
+	if games = parseLobbyGames(data.Panel{PanelName: "Container"}); len(games) != 0 {
+		t.Errorf("Expected no games for an empty list, got %+v", games)
+	}
+}
+
+func TestFindLobbyGameList(t *testing.T) {
+	container := lobbyContainer(lobbyRow("baal-1", "1/8", "", "Hell"))
+	panels := map[string]data.Panel{
+		"CharacterSelectPanel": {PanelName: "CharacterSelectPanel"},
+		"LobbyBackgroundPanel": {
+			PanelName: "LobbyBackgroundPanel",
+			PanelChildren: map[string]data.Panel{
+				"GamesTab": {
+					PanelName: "GamesTab",
+					PanelChildren: map[string]data.Panel{
+						"GameList": {
+							PanelName: "GameList",
+							PanelChildren: map[string]data.Panel{
+								"View": {PanelName: "View", PanelChildren: map[string]data.Panel{"Container": container}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	found, ok := findLobbyGameList(panels)
+	if !ok || found.PanelName != "Container" || found.NumChildren != 1 {
+		t.Errorf("Expected game list container, got %+v (found: %v)", found, ok)
+	}
+
+	if _, ok = findLobbyGameList(map[string]data.Panel{"CharacterSelectPanel": {PanelName: "CharacterSelectPanel"}}); ok {
+		t.Error("Expected game list not to be found")
+	}
+}
+
+func TestGameBlacklist(t *testing.T) {
+	ttl := 30 * time.Minute
+	now := time.Now()
+	blacklist := NewGameBlacklist(func() time.Duration { return ttl })
+	blacklist.now = func() time.Time { return now }
+
+	blacklist.Add("Baal-1")
+	if !blacklist.Contains("baal-1") {
+		t.Error("Game names should be case insensitive")
+	}
+	if blacklist.Contains("baal-2") {
+		t.Error("Game not joined should not be blacklisted")
+	}
+
+	now = now.Add(20 * time.Minute)
+	if !blacklist.Contains("baal-1") {
+		t.Error("Game should be blacklisted until the TTL expires")
+	}
+
+	// Config reloaded with a shorter TTL, games already in the list use it
+	ttl = 10 * time.Minute
+	if blacklist.Contains("baal-1") {
+		t.Error("Game should be removed once the new TTL expired")
+	}
+	if len(blacklist.games) != 0 {
+		t.Errorf("Expired games should be removed from the list, got %v", blacklist.games)
+	}
+}
+
+func TestFindGameToJoin(t *testing.T) {
+	games := []LobbyGame{
+		{Name: "joined-1", Players: 2, MaxPlayers: 8, Difficulty: difficulty.Hell},
+		{Name: "full-1", Players: 8, MaxPlayers: 8, Difficulty: difficulty.Hell},
+		{Name: "baal-nm", Players: 3, MaxPlayers: 8, Difficulty: difficulty.Nightmare},
+		{Name: "baal-high", Players: 4, MaxPlayers: 8, Difficulty: difficulty.Hell, LevelRequirement: 90},
+		{Name: "baal-hell", Players: 5, MaxPlayers: 8, Difficulty: difficulty.Hell, LevelRequirement: 60},
+		{Name: "cows-unknown", Players: 1, MaxPlayers: 8},
+	}
+
+	type filters struct {
+		namePattern         string
+		difficulty          difficulty.Difficulty
+		minPlayers          int
+		maxPlayers          int
+		maxLevelRequirement int
+	}
+
+	tests := []struct {
+		name     string
+		filters  filters
+		expected string
+		wantErr  bool
+	}{
+		{name: "No filters, skips joined and full games", expected: "baal-nm"},
+		{name: "Name pattern is case insensitive", filters: filters{namePattern: "^COWS"}, expected: "cows-unknown"},
+		{name: "Difficulty unknown is not skipped", filters: filters{difficulty: difficulty.Normal}, expected: "cows-unknown"},
+		{name: "Difficulty", filters: filters{difficulty: difficulty.Hell}, expected: "baal-high"},
+		{name: "Max level requirement", filters: filters{difficulty: difficulty.Hell, maxLevelRequirement: 80}, expected: "baal-hell"},
+		{name: "Min players", filters: filters{minPlayers: 4}, expected: "baal-high"},
+		{name: "Max players", filters: filters{maxPlayers: 1}, expected: "cows-unknown"},
+		{name: "No matches", filters: filters{namePattern: "chaos"}},
+		{name: "Invalid pattern", filters: filters{namePattern: "baal-("}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.CharacterCfg{}
+			cfg.Game.JoinGames.NamePattern = tt.filters.namePattern
+			cfg.Game.JoinGames.Difficulty = tt.filters.difficulty
+			cfg.Game.JoinGames.MinPlayers = tt.filters.minPlayers
+			cfg.Game.JoinGames.MaxPlayers = tt.filters.maxPlayers
+			cfg.Game.JoinGames.MaxLevelRequirement = tt.filters.maxLevelRequirement
+
+			blacklist := NewGameBlacklist(func() time.Duration { return time.Hour })
+			blacklist.Add("joined-1")
+
+			g, found, err := FindGameToJoin(games, cfg, blacklist)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if tt.expected == "" {
+				if found {
+					t.Errorf("Expected no game, got %+v", g)
+				}
+				return
+			}
+			if !found || g.Name != tt.expected {
+				t.Errorf("Expected %s, got %+v (found: %v)", tt.expected, g, found)
+			}
+		})
+	}
+}
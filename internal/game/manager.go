@@ -18,14 +18,18 @@ import (
 	"golang.org/x/sys/windows/registry"
 )
 
+// Game name can be already in use, in that case we try again with the next name
+const maxCreateGameAttempts = 3
+
 type Manager struct {
 	gr             *MemoryReader
 	hid            *HID
 	supervisorName string
+	gameNames      *gameNameGenerator
 }
 
 func NewGameManager(gr *MemoryReader, hid *HID, sueprvisorName string) *Manager {
-	return &Manager{gr: gr, hid: hid, supervisorName: sueprvisorName}
+	return &Manager{gr: gr, hid: hid, supervisorName: sueprvisorName, gameNames: &gameNameGenerator{supervisorName: sueprvisorName}}
 }
 
 func (gm *Manager) ExitGame() error {
@@ -106,8 +110,32 @@ func (gm *Manager) clearGameNameOrPasswordField() {
 	}
 }
 
-func (gm *Manager) CreateOnlineGame(gameCounter int) (string, error) {
+// CreateOnlineGame creates a new lobby game, returns the game name and password generated by the configured strategies
+func (gm *Manager) CreateOnlineGame() (string, string, error) {
+	cfg := config.Characters[gm.supervisorName]
+
+	var err error
+	for range maxCreateGameAttempts {
+		gameName, gamePassword := gm.gameNames.next(cfg)
+		if err = gm.createOnlineGame(cfg.Game.Difficulty, gameName, gamePassword); err == nil {
+			return gameName, gamePassword, nil
+		}
+
+		// Something else happened (bnet errors, queue...), it's not just the game name being taken
+		modal, text := gm.gr.IsDismissableModalPresent()
+		if !gm.gr.IsInLobby() || (modal && !strings.Contains(strings.ToLower(text), "already exists")) {
+			return gameName, gamePassword, err
+		}
+		if modal {
+			gm.hid.PressKey(win.VK_ESCAPE)
+			utils.Sleep(500)
+		}
+	}
 
+	return "", "", fmt.Errorf("error creating game after %d attempts: %w", maxCreateGameAttempts, err)
+}
+
+func (gm *Manager) createOnlineGame(gameDifficulty difficulty.Difficulty, gameName, gamePassword string) error {
 	// Click "Create game" tab
 	gm.hid.Click(LeftButton, 845, 54)
 	utils.Sleep(200)
@@ -120,14 +148,13 @@ func (gm *Manager) CreateOnlineGame(gameCounter int) (string, error) {
 		difficulty.Hell:      {X: 1065, Y: 252},
 	}
 
-	difficultyPos := difficultyPosition[config.Characters[gm.supervisorName].Game.Difficulty]
+	difficultyPos := difficultyPosition[gameDifficulty]
 	gm.hid.Click(LeftButton, difficultyPos.X, difficultyPos.Y)
 	utils.Sleep(200)
 
 	// Click the game name textbox, delete text and type new game name
 	gm.hid.Click(LeftButton, 1000, 116)
 	gm.clearGameNameOrPasswordField()
-	gameName := config.Characters[gm.supervisorName].Companion.GameNameTemplate + fmt.Sprintf("%d", gameCounter)
 	for _, ch := range gameName {
 		gm.hid.PressKey(gm.hid.GetASCIICode(fmt.Sprintf("%c", ch)))
 	}
@@ -135,23 +162,25 @@ func (gm *Manager) CreateOnlineGame(gameCounter int) (string, error) {
 	// Same for password
 	gm.hid.Click(LeftButton, 1000, 161)
 	utils.Sleep(200)
-	gamePassword := config.Characters[gm.supervisorName].Companion.GamePassword
-	if gamePassword != "" {
-		gm.clearGameNameOrPasswordField()
-		for _, ch := range gamePassword {
-			gm.hid.PressKey(gm.hid.GetASCIICode(fmt.Sprintf("%c", ch)))
-		}
+	// Always clear it, previous game password would be used otherwise
+	gm.clearGameNameOrPasswordField()
+	for _, ch := range gamePassword {
+		gm.hid.PressKey(gm.hid.GetASCIICode(fmt.Sprintf("%c", ch)))
 	}
 	gm.hid.PressKey(win.VK_RETURN)
 
 	for range 30 {
 		if gm.gr.InGame() {
-			return gameName, nil
+			return nil
+		}
+		// Game won't be created, no need to wait
+		if modal, text := gm.gr.IsDismissableModalPresent(); modal {
+			return fmt.Errorf("error creating game: %s", text)
 		}
 		utils.Sleep(1000)
 	}
 
-	return gameName, errors.New("error creating game! Timeout")
+	return errors.New("error creating game! Timeout")
 }
 
 func (gm *Manager) JoinOnlineGame(gameName, password string) error {
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/data/state"
+	"github.com/hectorgimenez/d2go/pkg/utils"
+)
+
+var (
+	auraStates  = []state.State{state.Conviction, state.Fanaticism, state.Might, state.Holyfire, state.Holyshock, state.Holywindcold, state.Concentration}
+	curseStates = []state.State{state.Amplifydamage, state.Decrepify, state.Weaken, state.Terror, state.Attract, state.Confuse}
+)
+
+// TargetScore returns how important is to kill the given monster first, based on the character targeting config.
+func (d Data) TargetScore(m data.Monster) int {
+	weights := d.CharacterCfg.Character.Targeting
+
+	score := 0
+	switch m.Type {
+	case data.MonsterTypeUnique, data.MonsterTypeSuperUnique:
+		score += weights.BossWeight
+	case data.MonsterTypeChampion:
+		score += weights.ChampionWeight
+	case data.MonsterTypeMinion:
+		score += weights.MinionWeight
+	}
+
+	if m.IsMonsterRaiser() {
+		score += weights.RaiserWeight
+	}
+
+	for _, s := range auraStates {
+		if m.States.HasState(s) {
+			score += weights.AuraWeight
+			break
+		}
+	}
+
+	for _, s := range curseStates {
+		if m.States.HasState(s) {
+			score += weights.CursedWeight
+			break
+		}
+	}
+
+	if maxLife := m.Stats[stat.MaxLife]; maxLife > 0 {
+		missingLifePct := 100 - m.Stats[stat.Life]*100/maxLife
+		score += weights.LowHealthWeight * max(missingLifePct, 0) / 100
+	}
+
+	score -= weights.DistanceWeight * utils.DistanceFromPoint(d.PlayerUnit.Position, m.Position)
+
+	return score
+}
+
+// SortByTargetPriority sorts the monsters in place, the first one is the best target. Monsters we can not damage
+// (isImmune returns true) are always the last ones no matter their score, isImmune can be nil.
+func (d Data) SortByTargetPriority(monsters []data.Monster, isImmune func(data.Monster) bool) {
+	scores := make(map[data.UnitID]int, len(monsters))
+	immune := make(map[data.UnitID]bool, len(monsters))
+	for _, m := range monsters {
+		scores[m.UnitID] = d.TargetScore(m)
+		immune[m.UnitID] = isImmune != nil && isImmune(m)
+	}
+
+	sort.SliceStable(monsters, func(i, j int) bool {
+		if immune[monsters[i].UnitID] != immune[monsters[j].UnitID] {
+			return !immune[monsters[i].UnitID]
+		}
+
+		return scores[monsters[i].UnitID] > scores[monsters[j].UnitID]
+	})
+}
+
+// BestTarget returns the monster with the highest target score, monsters we can not damage are only returned if there
+// is nothing else to attack
+func (d Data) BestTarget(monsters []data.Monster, isImmune func(data.Monster) bool) (data.Monster, bool) {
+	if len(monsters) == 0 {
+		return data.Monster{}, false
+	}
+
+	sorted := make([]data.Monster, len(monsters))
+	copy(sorted, monsters)
+	d.SortByTargetPriority(sorted, isImmune)
+
+	return sorted[0], true
+}
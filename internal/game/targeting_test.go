@@ -0,0 +1,71 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+)
+
+func targetingData() Data {
+	d := Data{}
+	d.PlayerUnit.Position = data.Position{X: 0, Y: 0}
+	d.CharacterCfg.Character.Targeting.BossWeight = 30
+	d.CharacterCfg.Character.Targeting.DistanceWeight = 2
+
+	return d
+}
+
+func TestSortByTargetPriorityImmunesLast(t *testing.T) {
+	d := targetingData()
+	coldImmune := func(m data.Monster) bool { return m.IsImmune(stat.ColdImmune) }
+
+	monsters := []data.Monster{
+		// Close boss, but immune, its score is way higher than the rest
+		{UnitID: 1, Name: npc.CouncilMember, Type: data.MonsterTypeUnique, Position: data.Position{X: 1, Y: 0}, Stats: map[stat.ID]int{stat.ColdResist: 100}},
+		{UnitID: 2, Name: npc.CouncilMember, Position: data.Position{X: 40, Y: 0}},
+		{UnitID: 3, Name: npc.CouncilMember, Position: data.Position{X: 20, Y: 0}},
+	}
+
+	d.SortByTargetPriority(monsters, coldImmune)
+
+	expected := []data.UnitID{3, 2, 1}
+	for i, id := range expected {
+		if monsters[i].UnitID != id {
+			t.Fatalf("position %d: expected monster %d, got %d", i, id, monsters[i].UnitID)
+		}
+	}
+}
+
+func TestSortByTargetPriorityWithoutImmunities(t *testing.T) {
+	d := targetingData()
+	monsters := []data.Monster{
+		{UnitID: 1, Position: data.Position{X: 30, Y: 0}},
+		{UnitID: 2, Type: data.MonsterTypeUnique, Position: data.Position{X: 35, Y: 0}},
+		{UnitID: 3, Position: data.Position{X: 10, Y: 0}},
+	}
+
+	d.SortByTargetPriority(monsters, nil)
+
+	expected := []data.UnitID{3, 2, 1}
+	for i, id := range expected {
+		if monsters[i].UnitID != id {
+			t.Fatalf("position %d: expected monster %d, got %d", i, id, monsters[i].UnitID)
+		}
+	}
+}
+
+func TestBestTarget(t *testing.T) {
+	d := targetingData()
+	if _, found := d.BestTarget(nil, nil); found {
+		t.Errorf("expected no target for an empty list")
+	}
+
+	// Immune monsters are still returned when there is nothing else
+	immune := []data.Monster{{UnitID: 1, Stats: map[stat.ID]int{stat.ColdResist: 100}}}
+	m, found := d.BestTarget(immune, func(m data.Monster) bool { return true })
+	if !found || m.UnitID != 1 {
+		t.Errorf("expected immune monster as the only target, got %d (found %t)", m.UnitID, found)
+	}
+}
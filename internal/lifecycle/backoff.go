@@ -0,0 +1,57 @@
+package lifecycle
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff counts the consecutive bnet failures, it should be kept between flows, it's reset once we get in game
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+	// MaxFailures is the number of consecutive failures before restarting the client, 0 means never
+	MaxFailures int
+	// Jitter is the max random variation of every delay, 0.2 means +-20%
+	Jitter float64
+
+	failures int
+}
+
+func DefaultBackoff() *Backoff {
+	return &Backoff{
+		Base:        30 * time.Second,
+		Max:         10 * time.Minute,
+		MaxFailures: 5,
+		Jitter:      0.2,
+	}
+}
+
+// Next registers a new failure and returns the time to wait before trying again
+func (b *Backoff) Next() time.Duration {
+	b.failures++
+
+	delay := b.Base
+	for i := 1; i < b.failures && delay < b.Max; i++ {
+		delay *= 2
+	}
+	delay = min(delay, b.Max)
+
+	// Jitter avoids all the supervisors retrying at the same time
+	if b.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * b.Jitter * float64(delay))
+	}
+
+	return delay
+}
+
+func (b *Backoff) Failures() int {
+	return b.failures
+}
+
+func (b *Backoff) ShouldRestartClient() bool {
+	return b.MaxFailures > 0 && b.failures >= b.MaxFailures
+}
+
+func (b *Backoff) Reset() {
+	b.failures = 0
+}
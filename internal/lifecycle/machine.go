@@ -0,0 +1,294 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Reader tells in which screen the game client is
+type Reader interface {
+	InGame() bool
+	IsLoading() bool
+	IsInCharacterSelectionScreen() bool
+	IsInLobby() bool
+	IsOnline() bool
+	// ModalMessage returns the text of the popup shown by the game client, false if there is no popup
+	ModalMessage() (bool, string)
+}
+
+// Actions are the interactions needed to move from one state to the next one, errors are not final, once the action
+// returns the machine checks again in which state we are and the action is retried if needed
+type Actions interface {
+	Reconnect() error
+	EnterLobby() error
+	LeaveLobby() error
+	CreateGame() error
+	CreateLobbyGame() error
+	JoinLobbyGame() error
+	ExitGame() error
+	KillClient() error
+	DismissModal() error
+}
+
+// Machine drives the game client from wherever it is to a new game
+type Machine struct {
+	reader  Reader
+	actions Actions
+	mode    Mode
+	// online is false for offline characters, they don't need bnet connection
+	online          bool
+	Policies        map[State]Policy
+	Backoff         *Backoff
+	OnTransition    func(Transition)
+	OnOnlineFailure func(OnlineFailure)
+
+	// ctx is the one given to Run, waits are interrupted when it's cancelled
+	ctx       context.Context
+	state     State
+	enteredAt time.Time
+	attempts  map[State]int
+	lastErr   error
+	modal     string
+
+	// Replaced in tests, so we don't need to wait
+	sleep func(context.Context, time.Duration) error
+	now   func() time.Time
+}
+
+func New(reader Reader, actions Actions, mode Mode, online bool) *Machine {
+	return &Machine{
+		reader:   reader,
+		actions:  actions,
+		mode:     mode,
+		online:   online,
+		Policies: DefaultPolicies(),
+		Backoff:  DefaultBackoff(),
+		state:    Launching,
+		attempts: make(map[State]int),
+		ctx:      context.Background(),
+		sleep:    sleepContext,
+		now:      time.Now,
+	}
+}
+
+func (m *Machine) State() State {
+	return m.state
+}
+
+// Run returns once we are in game, or with a StateError if we can not get there
+func (m *Machine) Run(ctx context.Context) error {
+	m.ctx = ctx
+	defer func() { m.ctx = context.Background() }()
+	m.attempts = make(map[State]int)
+	m.lastErr = nil
+	m.enteredAt = m.now()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// If the game was not created we will be back in the previous screen, attempts are kept during the whole flow
+		state := m.detect()
+		m.transition(state, "detected")
+		if state == InGame {
+			m.Backoff.Reset()
+			return nil
+		}
+
+		if err := m.step(); err != nil {
+			return err
+		}
+	}
+}
+
+// Exit leaves the current game, it returns once we are out of the game
+func (m *Machine) Exit() error {
+	m.attempts = make(map[State]int)
+	m.lastErr = nil
+	m.transition(Exiting, "exit requested")
+
+	for m.reader.InGame() {
+		if err := m.step(); err != nil {
+			return err
+		}
+	}
+	m.transition(m.detect(), "game exited")
+
+	return nil
+}
+
+func (m *Machine) detect() State {
+	present, message := m.reader.ModalMessage()
+	if present {
+		m.modal = message
+		if state, found := ModalState(message); found {
+			return state
+		}
+		return Modal
+	}
+	m.modal = ""
+
+	switch {
+	case m.reader.InGame():
+		return InGame
+	case m.reader.IsLoading():
+		return Loading
+	case m.reader.IsInCharacterSelectionScreen():
+		if m.online && !m.reader.IsOnline() {
+			return Offline
+		}
+		return CharSelect
+	case m.reader.IsInLobby():
+		return Lobby
+	}
+
+	return Launching
+}
+
+func (m *Machine) step() error {
+	policy := m.Policies[m.state]
+	if policy.Timeout > 0 && m.now().Sub(m.enteredAt) > policy.Timeout {
+		return &StateError{State: m.state, Err: fmt.Errorf("%w after %s", ErrTimeout, policy.Timeout)}
+	}
+
+	switch m.state {
+	case Offline:
+		// Previous reconnection failed
+		if m.attempts[Offline] > 0 {
+			if err := m.onlineFailure(); err != nil {
+				return err
+			}
+		}
+		m.attempts[Offline]++
+		return m.do(policy, m.actions.Reconnect)
+	case RateLimited, RealmDown:
+		return m.onlineFailure()
+	case Modal:
+		m.lastErr = errors.New(m.modal)
+		return m.do(policy, m.actions.DismissModal)
+	case CharSelect:
+		if m.mode == ModeCreateGame {
+			return m.createGame(m.actions.CreateGame)
+		}
+		if err := m.attempt(CharSelect); err != nil {
+			return err
+		}
+		return m.do(policy, m.actions.EnterLobby)
+	case Lobby:
+		switch m.mode {
+		case ModeCreateLobbyGame:
+			return m.createGame(m.actions.CreateLobbyGame)
+		case ModeJoinLobbyGame:
+			return m.createGame(m.actions.JoinLobbyGame)
+		}
+		if err := m.attempt(Lobby); err != nil {
+			return err
+		}
+		return m.do(policy, m.actions.LeaveLobby)
+	case Exiting:
+		if err := m.attempt(Exiting); err != nil {
+			return err
+		}
+		return m.do(policy, m.actions.ExitGame)
+	default:
+		// Launching, Loading and Queued, nothing to do but wait
+		_ = m.sleep(m.ctx, policy.RetryDelay)
+	}
+
+	return nil
+}
+
+// onlineFailure waits before trying again, or restarts the client if bnet failed too many times in a row
+func (m *Machine) onlineFailure() error {
+	message := m.modal
+	if m.state == Offline {
+		message = "not connected to bnet"
+	}
+
+	failure := OnlineFailure{State: m.state, Message: message, Backoff: m.Backoff.Next()}
+	failure.Failures = m.Backoff.Failures()
+	failure.RestartClient = m.Backoff.ShouldRestartClient()
+	if m.OnOnlineFailure != nil {
+		m.OnOnlineFailure(failure)
+	}
+
+	if failure.RestartClient {
+		m.Backoff.Reset()
+		m.transition(Disconnected, fmt.Sprintf("%d consecutive bnet failures", failure.Failures))
+		if err := m.actions.KillClient(); err != nil {
+			return &StateError{State: Disconnected, Err: err}
+		}
+		return &StateError{State: Disconnected, Err: fmt.Errorf("%w: %s", ErrDisconnected, message)}
+	}
+
+	if m.state != Offline {
+		_ = m.actions.DismissModal()
+	}
+	// Game was not created because of bnet, it doesn't count as a failed attempt
+	m.attempts[CreatingGame] = 0
+
+	// Backoff can take minutes, the supervisor can be stopped meanwhile
+	return m.sleep(m.ctx, failure.Backoff)
+}
+
+func (m *Machine) createGame(action func() error) error {
+	if err := m.attempt(CreatingGame); err != nil {
+		return err
+	}
+	m.transition(CreatingGame, "creating game")
+
+	return m.do(m.Policies[CreatingGame], action)
+}
+
+// do executes the action, errors are not returned, the state will be checked again and the action retried if needed
+func (m *Machine) do(policy Policy, action func() error) error {
+	if err := action(); err != nil {
+		m.lastErr = err
+	}
+	_ = m.sleep(m.ctx, policy.RetryDelay)
+
+	return nil
+}
+
+// sleepContext waits for the given duration, it returns the context error if it's cancelled before
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (m *Machine) attempt(state State) error {
+	m.attempts[state]++
+	if m.attempts[state] <= m.Policies[state].MaxAttempts {
+		return nil
+	}
+
+	err := fmt.Errorf("%w (%d)", ErrMaxAttempts, m.Policies[state].MaxAttempts)
+	if m.lastErr != nil {
+		err = fmt.Errorf("%w, last error: %w", err, m.lastErr)
+	}
+
+	return &StateError{State: state, Err: err}
+}
+
+func (m *Machine) transition(to State, reason string) {
+	if m.state == to {
+		return
+	}
+
+	t := Transition{From: m.state, To: to, Reason: reason}
+	m.state = to
+	m.enteredAt = m.now()
+	if m.OnTransition != nil {
+		m.OnTransition(t)
+	}
+}
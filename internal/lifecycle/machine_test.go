@@ -0,0 +1,426 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeClient simulates the game client screens, actions move it to the next screen unless they are set to fail
+type fakeClient struct {
+	screen       State
+	online       bool
+	loadingTicks int
+
+	reconnectFails bool
+	createErr      error
+	// createModals are shown, one per attempt, instead of creating the game
+	createModals []string
+	modal        string
+	// realmDownModal is shown again every time it's dismissed
+	realmDownModal string
+	calls          []string
+}
+
+func (f *fakeClient) InGame() bool {
+	return f.screen == InGame
+}
+
+func (f *fakeClient) IsLoading() bool {
+	if f.screen != Loading {
+		return false
+	}
+	// Loading screen is over after some checks, unless it's stuck
+	if f.loadingTicks > 0 {
+		f.loadingTicks--
+		if f.loadingTicks == 0 {
+			f.screen = InGame
+		}
+	}
+
+	return true
+}
+
+func (f *fakeClient) IsInCharacterSelectionScreen() bool {
+	return f.screen == CharSelect
+}
+
+func (f *fakeClient) IsInLobby() bool {
+	return f.screen == Lobby
+}
+
+func (f *fakeClient) IsOnline() bool {
+	return f.online
+}
+
+func (f *fakeClient) ModalMessage() (bool, string) {
+	if f.modal == "" && f.realmDownModal != "" {
+		return true, f.realmDownModal
+	}
+
+	return f.modal != "", f.modal
+}
+
+func (f *fakeClient) DismissModal() error {
+	f.calls = append(f.calls, "dismiss_modal")
+	f.modal = ""
+	return nil
+}
+
+func (f *fakeClient) Reconnect() error {
+	f.calls = append(f.calls, "reconnect")
+	if !f.reconnectFails {
+		f.online = true
+	}
+	return nil
+}
+
+func (f *fakeClient) EnterLobby() error {
+	f.calls = append(f.calls, "enter_lobby")
+	f.screen = Lobby
+	return nil
+}
+
+func (f *fakeClient) LeaveLobby() error {
+	f.calls = append(f.calls, "leave_lobby")
+	f.screen = CharSelect
+	return nil
+}
+
+func (f *fakeClient) CreateGame() error {
+	f.calls = append(f.calls, "create_game")
+	return f.startGame()
+}
+
+func (f *fakeClient) CreateLobbyGame() error {
+	f.calls = append(f.calls, "create_lobby_game")
+	return f.startGame()
+}
+
+func (f *fakeClient) JoinLobbyGame() error {
+	f.calls = append(f.calls, "join_lobby_game")
+	return f.startGame()
+}
+
+func (f *fakeClient) ExitGame() error {
+	f.calls = append(f.calls, "exit_game")
+	f.screen = CharSelect
+	return nil
+}
+
+func (f *fakeClient) KillClient() error {
+	f.calls = append(f.calls, "kill_client")
+	f.screen = Launching
+	return nil
+}
+
+func (f *fakeClient) startGame() error {
+	if f.createErr != nil {
+		return f.createErr
+	}
+	if len(f.createModals) > 0 {
+		f.modal = f.createModals[0]
+		f.createModals = f.createModals[1:]
+		return errors.New(f.modal)
+	}
+	f.screen = Loading
+	return nil
+}
+
+// newTestMachine uses a fake clock advanced by the machine sleeps, so timeouts don't need real time
+func newTestMachine(f *fakeClient, mode Mode, online bool) (*Machine, *[]State) {
+	m := New(f, f, mode, online)
+	m.Backoff.Jitter = 0
+
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return clock }
+	m.sleep = func(_ context.Context, d time.Duration) error {
+		clock = clock.Add(d)
+		return nil
+	}
+
+	states := []State{m.State()}
+	m.OnTransition = func(t Transition) {
+		states = append(states, t.To)
+	}
+
+	return m, &states
+}
+
+func TestCreateGameFromCharacterSelection(t *testing.T) {
+	f := &fakeClient{screen: CharSelect, loadingTicks: 3}
+	m, states := newTestMachine(f, ModeCreateGame, false)
+
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []State{Launching, CharSelect, CreatingGame, Loading, InGame}
+	if !slices.Equal(*states, expected) {
+		t.Errorf("Expected transitions %v, got %v", expected, *states)
+	}
+	if !slices.Equal(f.calls, []string{"create_game"}) {
+		t.Errorf("Expected only create_game to be called, got %v", f.calls)
+	}
+}
+
+func TestLeaveLobbyWhenCreatingGamesFromCharacterSelection(t *testing.T) {
+	f := &fakeClient{screen: Lobby, online: true, loadingTicks: 1}
+	m, _ := newTestMachine(f, ModeCreateGame, true)
+
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !slices.Equal(f.calls, []string{"leave_lobby", "create_game"}) {
+		t.Errorf("Expected to leave the lobby before creating the game, got %v", f.calls)
+	}
+}
+
+func TestReconnectAndJoinLobbyGame(t *testing.T) {
+	f := &fakeClient{screen: CharSelect, online: false, loadingTicks: 1}
+	m, states := newTestMachine(f, ModeJoinLobbyGame, true)
+
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []State{Launching, Offline, CharSelect, Lobby, CreatingGame, Loading, InGame}
+	if !slices.Equal(*states, expected) {
+		t.Errorf("Expected transitions %v, got %v", expected, *states)
+	}
+	if !slices.Equal(f.calls, []string{"reconnect", "enter_lobby", "join_lobby_game"}) {
+		t.Errorf("Unexpected actions %v", f.calls)
+	}
+}
+
+func TestKillClientWhenReconnectFails(t *testing.T) {
+	f := &fakeClient{screen: CharSelect, reconnectFails: true}
+	m, _ := newTestMachine(f, ModeCreateLobbyGame, true)
+	m.Backoff.MaxFailures = 1
+
+	err := m.Run(context.Background())
+	if !errors.Is(err, ErrDisconnected) {
+		t.Fatalf("Expected disconnected error, got %v", err)
+	}
+	if m.State() != Disconnected {
+		t.Errorf("Expected disconnected state, got %s", m.State())
+	}
+	if !slices.Equal(f.calls, []string{"reconnect", "kill_client"}) {
+		t.Errorf("Expected a single reconnect attempt before killing the client, got %v", f.calls)
+	}
+}
+
+func TestCreateGameMaxAttempts(t *testing.T) {
+	createErr := errors.New("game name already in use")
+	f := &fakeClient{screen: Lobby, online: true, createErr: createErr}
+	m, _ := newTestMachine(f, ModeCreateLobbyGame, true)
+
+	err := m.Run(context.Background())
+	if !errors.Is(err, ErrMaxAttempts) {
+		t.Fatalf("Expected max attempts error, got %v", err)
+	}
+	if !errors.Is(err, createErr) {
+		t.Errorf("Expected error to wrap the last action error, got %v", err)
+	}
+
+	var stateErr *StateError
+	if !errors.As(err, &stateErr) || stateErr.State != CreatingGame {
+		t.Errorf("Expected error in creating game state, got %v", err)
+	}
+
+	attempts := m.Policies[CreatingGame].MaxAttempts
+	if len(f.calls) != attempts {
+		t.Errorf("Expected %d attempts, got %d", attempts, len(f.calls))
+	}
+}
+
+func TestLoadingTimeout(t *testing.T) {
+	// Loading screen never ends
+	f := &fakeClient{screen: Loading}
+	m, _ := newTestMachine(f, ModeCreateGame, false)
+
+	err := m.Run(context.Background())
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected timeout error, got %v", err)
+	}
+	if m.State() != Loading {
+		t.Errorf("Expected timeout in loading state, got %s", m.State())
+	}
+}
+
+func TestRunStopsWhenContextIsCancelled(t *testing.T) {
+	f := &fakeClient{screen: Launching}
+	m, _ := newTestMachine(f, ModeCreateGame, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := m.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled error, got %v", err)
+	}
+}
+
+func TestExit(t *testing.T) {
+	f := &fakeClient{screen: InGame, online: true}
+	m, states := newTestMachine(f, ModeCreateLobbyGame, true)
+
+	if err := m.Exit(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []State{Launching, Exiting, CharSelect}
+	if !slices.Equal(*states, expected) {
+		t.Errorf("Expected transitions %v, got %v", expected, *states)
+	}
+}
+
+func TestRateLimitBacksOffAndRetries(t *testing.T) {
+	f := &fakeClient{
+		screen:       Lobby,
+		online:       true,
+		loadingTicks: 1,
+		// More rate limits than create attempts, they should not count as failed attempts
+		createModals: []string{"You have been creating games too fast", "You have been creating games too fast", "You have been creating games too fast"},
+	}
+	m, states := newTestMachine(f, ModeCreateLobbyGame, true)
+
+	var failures []OnlineFailure
+	m.OnOnlineFailure = func(failure OnlineFailure) {
+		failures = append(failures, failure)
+	}
+
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(failures) != 3 {
+		t.Fatalf("Expected 3 online failures, got %d", len(failures))
+	}
+	for i, failure := range failures {
+		if failure.State != RateLimited || failure.Failures != i+1 || failure.RestartClient {
+			t.Errorf("Unexpected failure %d: %+v", i, failure)
+		}
+	}
+	if failures[1].Backoff != 2*failures[0].Backoff || failures[2].Backoff != 2*failures[1].Backoff {
+		t.Errorf("Expected exponential backoff, got %s, %s, %s", failures[0].Backoff, failures[1].Backoff, failures[2].Backoff)
+	}
+	if !slices.Contains(*states, RateLimited) {
+		t.Errorf("Expected rate limited state, got %v", *states)
+	}
+	if m.Backoff.Failures() != 0 {
+		t.Errorf("Expected failures to be reset once in game, got %d", m.Backoff.Failures())
+	}
+}
+
+func TestRealmDownRestartsClientAfterMaxFailures(t *testing.T) {
+	f := &fakeClient{screen: Lobby, online: true, realmDownModal: "Unable to connect to Battle.net"}
+	m, _ := newTestMachine(f, ModeCreateLobbyGame, true)
+	m.Backoff.MaxFailures = 3
+
+	restarted := false
+	m.OnOnlineFailure = func(failure OnlineFailure) {
+		if failure.State != RealmDown {
+			t.Errorf("Expected realm down failure, got %s", failure.State)
+		}
+		restarted = failure.RestartClient
+	}
+
+	err := m.Run(context.Background())
+	if !errors.Is(err, ErrDisconnected) {
+		t.Fatalf("Expected disconnected error, got %v", err)
+	}
+	if !restarted || !slices.Contains(f.calls, "kill_client") {
+		t.Errorf("Expected client to be restarted, actions: %v", f.calls)
+	}
+	if dismissed := len(slices.DeleteFunc(slices.Clone(f.calls), func(c string) bool { return c != "dismiss_modal" })); dismissed != 2 {
+		t.Errorf("Expected popup to be dismissed before every retry, dismissed %d times", dismissed)
+	}
+}
+
+func TestBackoffStopsWhenContextIsCancelled(t *testing.T) {
+	f := &fakeClient{screen: Lobby, online: true, realmDownModal: "Unable to connect to Battle.net"}
+	m, _ := newTestMachine(f, ModeCreateLobbyGame, true)
+	m.Backoff.Base = time.Hour
+	m.Backoff.Max = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Supervisor is stopped while waiting for bnet, real sleep is used so the wait needs to be interrupted
+	m.sleep = func(ctx context.Context, d time.Duration) error {
+		if d == time.Hour {
+			cancel()
+		}
+		return sleepContext(ctx, d)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context canceled error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Backoff didn't stop when the context was cancelled")
+	}
+}
+
+func TestQueueWaitsWithoutRetrying(t *testing.T) {
+	f := &fakeClient{screen: Lobby, online: true, modal: "Creating game. Your position in queue: 12"}
+	m, _ := newTestMachine(f, ModeCreateLobbyGame, true)
+
+	checks := 0
+	m.sleep = func(context.Context, time.Duration) error {
+		checks++
+		if checks == 5 {
+			f.modal = ""
+			f.screen = InGame
+		}
+		return nil
+	}
+
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(f.calls) != 0 {
+		t.Errorf("Expected no actions while queued, got %v", f.calls)
+	}
+}
+
+func TestUnknownModalIsDismissed(t *testing.T) {
+	f := &fakeClient{screen: Lobby, online: true, loadingTicks: 1, createModals: []string{"Game is full"}}
+	m, _ := newTestMachine(f, ModeJoinLobbyGame, true)
+
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !slices.Equal(f.calls, []string{"join_lobby_game", "dismiss_modal", "join_lobby_game"}) {
+		t.Errorf("Expected popup to be dismissed and join retried, got %v", f.calls)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := &Backoff{Base: 10 * time.Second, Max: time.Minute, MaxFailures: 5, Jitter: 0.2}
+
+	expected := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}
+	for i, e := range expected {
+		delay := b.Next()
+		if delay < time.Duration(float64(e)*0.8) || delay > time.Duration(float64(e)*1.2) {
+			t.Errorf("Failure %d: expected %s +-20%%, got %s", i+1, e, delay)
+		}
+		if restart := b.ShouldRestartClient(); restart != (i+1 == b.MaxFailures) {
+			t.Errorf("Failure %d: unexpected restart %v", i+1, restart)
+		}
+	}
+
+	b.Reset()
+	if b.Failures() != 0 || b.ShouldRestartClient() {
+		t.Errorf("Expected backoff to be reset")
+	}
+}
@@ -0,0 +1,117 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// State is the screen where the game client is when we are not playing
+type State string
+
+const (
+	// Launching is any screen before the character selection (splash, intro videos, connecting...)
+	Launching    State = "launching"
+	CharSelect   State = "char_select"
+	Offline      State = "offline"
+	Lobby        State = "lobby"
+	CreatingGame State = "creating_game"
+	Loading      State = "loading"
+	InGame       State = "in_game"
+	Exiting      State = "exiting"
+	Disconnected State = "disconnected"
+	// Queued is the game creation queue, we just need to wait until our turn
+	Queued State = "queued"
+	// RateLimited is shown when games are created too fast
+	RateLimited State = "rate_limited"
+	RealmDown   State = "realm_down"
+	// Modal is any other popup (e.g. game is full), it's dismissed and the previous action is retried
+	Modal State = "modal"
+)
+
+// Mode is the way games are started
+type Mode string
+
+const (
+	ModeCreateGame      Mode = "create_game"
+	ModeCreateLobbyGame Mode = "create_lobby_game"
+	ModeJoinLobbyGame   Mode = "join_lobby_game"
+)
+
+var (
+	ErrTimeout      = errors.New("timeout")
+	ErrMaxAttempts  = errors.New("max attempts reached")
+	ErrDisconnected = errors.New("lost connection to bnet")
+)
+
+// Policy controls how long we can stay in a state and how many times the action of the state is retried
+type Policy struct {
+	// Timeout is the max time in the state, 0 means no timeout
+	Timeout time.Duration
+	// MaxAttempts is the max number of times the action of the state is executed in the same flow
+	MaxAttempts int
+	// RetryDelay is the time to wait after every attempt, or between checks for states without action
+	RetryDelay time.Duration
+}
+
+func DefaultPolicies() map[State]Policy {
+	return map[State]Policy{
+		Launching:    {Timeout: 2 * time.Minute, RetryDelay: time.Second},
+		Offline:      {RetryDelay: 4 * time.Second},
+		CharSelect:   {MaxAttempts: 5, RetryDelay: time.Second},
+		Lobby:        {MaxAttempts: 5, RetryDelay: time.Second},
+		CreatingGame: {MaxAttempts: 3, RetryDelay: time.Second},
+		Loading:      {Timeout: time.Minute, RetryDelay: 250 * time.Millisecond},
+		Exiting:      {MaxAttempts: 1, Timeout: 30 * time.Second, RetryDelay: time.Second},
+		Queued:       {Timeout: 30 * time.Minute, RetryDelay: 5 * time.Second},
+		Modal:        {RetryDelay: time.Second},
+	}
+}
+
+// ModalState returns the state matching the message shown in the game client modal, false for unknown messages
+func ModalState(message string) (State, bool) {
+	message = strings.ToLower(message)
+	switch {
+	case strings.Contains(message, "queue") || strings.Contains(message, "position in line"):
+		return Queued, true
+	case strings.Contains(message, "too fast") || strings.Contains(message, "too many"):
+		return RateLimited, true
+	case strings.Contains(message, "unable to connect") || strings.Contains(message, "realm") ||
+		strings.Contains(message, "unavailable") || strings.Contains(message, "lost connection") ||
+		strings.Contains(message, "disconnected"):
+		return RealmDown, true
+	}
+
+	return "", false
+}
+
+// OnlineFailure is reported every time bnet fails, Backoff is the time we wait before trying again
+type OnlineFailure struct {
+	State         State
+	Message       string
+	Failures      int
+	Backoff       time.Duration
+	RestartClient bool
+}
+
+// Transition is reported every time the state changes
+type Transition struct {
+	From   State
+	To     State
+	Reason string
+}
+
+// StateError is returned when the flow can not continue, Err wraps ErrTimeout, ErrMaxAttempts or ErrDisconnected
+type StateError struct {
+	State State
+	Err   error
+}
+
+func (e *StateError) Error() string {
+	return fmt.Sprintf("%s: %s", e.State, e.Err.Error())
+}
+
+func (e *StateError) Unwrap() error {
+	return e.Err
+}
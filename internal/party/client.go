@@ -0,0 +1,88 @@
+package party
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client is used by followers to connect to the leader koolo instance
+type Client struct {
+	conn     *websocket.Conn
+	party    string
+	name     string
+	mu       sync.Mutex
+	messages chan Message
+}
+
+// Dial connects to the party server, address is the host and port of the leader koolo web server (e.g. 192.168.1.10:8087)
+// and secret the party secret configured in the leader instance
+func Dial(ctx context.Context, address, secret, party, name string) (*Client, error) {
+	u := url.URL{
+		Scheme:   "ws",
+		Host:     address,
+		Path:     "/party",
+		RawQuery: url.Values{"party": {party}, "name": {name}}.Encode(),
+	}
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, u.String(), http.Header{SecretHeader: {secret}})
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("error connecting to party server %s: %w (%s)", address, err, resp.Status)
+		}
+		return nil, fmt.Errorf("error connecting to party server %s: %w", address, err)
+	}
+
+	c := &Client{
+		conn:     conn,
+		party:    party,
+		name:     name,
+		messages: make(chan Message, 100),
+	}
+	go c.readPump()
+
+	return c, nil
+}
+
+// Send sends the message to the leader, party and sender are filled by the client
+func (c *Client) Send(msg Message) error {
+	msg.Party = c.party
+	msg.From = c.name
+	if msg.SentAt.IsZero() {
+		msg.SentAt = time.Now()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+	return c.conn.WriteJSON(msg)
+}
+
+// Messages returns the messages sent by the leader, the channel is closed when the connection is lost
+func (c *Client) Messages() <-chan Message {
+	return c.messages
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) readPump() {
+	defer close(c.messages)
+
+	for {
+		var msg Message
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		c.messages <- msg
+	}
+}
@@ -0,0 +1,33 @@
+package party
+
+import (
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+)
+
+type MessageType string
+
+const (
+	// MessageGameCreated is sent by the leader with the game name and password
+	MessageGameCreated MessageType = "game_created"
+	// MessageReady is sent by followers waiting for a game
+	MessageReady MessageType = "ready"
+	// MessageJoined is sent by followers once they are in the leader game
+	MessageJoined MessageType = "joined"
+	// MessagePortal is sent by the leader when a town portal is opened, Area is where the portal leads to
+	MessagePortal MessageType = "portal"
+	// MessageLeaving is sent by anyone leaving the game, followers will leave when the leader does
+	MessageLeaving MessageType = "leaving"
+)
+
+// Message is exchanged between the leader koolo instance and the followers, Party is the leader name
+type Message struct {
+	Type     MessageType `json:"type"`
+	Party    string      `json:"party"`
+	From     string      `json:"from"`
+	Game     string      `json:"game,omitempty"`
+	Password string      `json:"password,omitempty"`
+	Area     area.ID     `json:"area,omitempty"`
+	SentAt   time.Time   `json:"sentAt"`
+}
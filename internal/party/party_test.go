@@ -0,0 +1,165 @@
+package party
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+)
+
+const testSecret = "party-secret"
+
+// newInstance starts a party server the same way the koolo web server does
+func newInstance(t *testing.T, secret string) (*Server, string) {
+	srv := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), func() string { return secret })
+	mux := http.NewServeMux()
+	mux.Handle("/party", srv)
+
+	httpSrv := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		srv.Close()
+		httpSrv.Close()
+	})
+
+	return srv, strings.TrimPrefix(httpSrv.URL, "http://")
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for message")
+	}
+
+	var zero T
+	return zero
+}
+
+func TestLoopbackParty(t *testing.T) {
+	leader, leaderAddress := newInstance(t, testSecret)
+
+	follower, err := Dial(context.Background(), leaderAddress, testSecret, "Leader", "Follower")
+	if err != nil {
+		t.Fatalf("Error connecting to the leader: %v", err)
+	}
+	defer follower.Close()
+
+	// Another party connected to the same instance should not receive our messages
+	stranger, err := Dial(context.Background(), leaderAddress, testSecret, "OtherLeader", "Stranger")
+	if err != nil {
+		t.Fatalf("Error connecting to the leader: %v", err)
+	}
+	defer stranger.Close()
+
+	if err = follower.Send(Message{Type: MessageReady}); err != nil {
+		t.Fatalf("Error sending ready: %v", err)
+	}
+	msg := receive(t, leader.Messages())
+	if msg.Type != MessageReady || msg.From != "Follower" || msg.Party != "Leader" {
+		t.Errorf("Expected ready message from Follower in party Leader, got %+v", msg)
+	}
+
+	leader.Publish(Message{Type: MessageGameCreated, Party: "Leader", From: "LeaderChar", Game: "game-1", Password: "xxx"})
+	msg = receive(t, follower.Messages())
+	if msg.Type != MessageGameCreated || msg.Game != "game-1" || msg.Password != "xxx" {
+		t.Errorf("Expected game created message with game name and password, got %+v", msg)
+	}
+
+	if err = follower.Send(Message{Type: MessageJoined, Game: "game-1"}); err != nil {
+		t.Fatalf("Error sending joined: %v", err)
+	}
+	msg = receive(t, leader.Messages())
+	if msg.Type != MessageJoined || msg.Game != "game-1" {
+		t.Errorf("Expected joined message, got %+v", msg)
+	}
+
+	leader.Publish(Message{Type: MessagePortal, Party: "Leader", From: "LeaderChar", Area: area.DuranceOfHateLevel3})
+	msg = receive(t, follower.Messages())
+	if msg.Type != MessagePortal || msg.Area != area.DuranceOfHateLevel3 {
+		t.Errorf("Expected portal message to Durance of Hate, got %+v", msg)
+	}
+
+	leader.Publish(Message{Type: MessageLeaving, Party: "Leader", From: "LeaderChar"})
+	msg = receive(t, follower.Messages())
+	if msg.Type != MessageLeaving {
+		t.Errorf("Expected leaving message, got %+v", msg)
+	}
+
+	select {
+	case msg = <-stranger.Messages():
+		t.Errorf("Member of another party received a message: %+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestClientMessagesClosedOnDisconnect(t *testing.T) {
+	leader, leaderAddress := newInstance(t, testSecret)
+
+	follower, err := Dial(context.Background(), leaderAddress, testSecret, "Leader", "Follower")
+	if err != nil {
+		t.Fatalf("Error connecting to the leader: %v", err)
+	}
+	defer follower.Close()
+
+	leader.Close()
+
+	select {
+	case _, open := <-follower.Messages():
+		if open {
+			t.Errorf("Expected messages channel to be closed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for the connection to be closed")
+	}
+}
+
+func TestSecretRequired(t *testing.T) {
+	_, leaderAddress := newInstance(t, testSecret)
+	// Instances without a secret don't accept anyone
+	_, noSecretAddress := newInstance(t, "")
+
+	tests := []struct {
+		name    string
+		address string
+		secret  string
+	}{
+		{name: "Missing secret", address: leaderAddress, secret: ""},
+		{name: "Wrong secret", address: leaderAddress, secret: "wrong"},
+		{name: "Instance without secret", address: noSecretAddress, secret: ""},
+		{name: "Instance without secret, secret sent", address: noSecretAddress, secret: testSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := Dial(context.Background(), tt.address, tt.secret, "Leader", "Follower")
+			if err == nil {
+				client.Close()
+				t.Fatal("Expected connection to be rejected")
+			}
+		})
+	}
+}
+
+func TestCrossOriginRejected(t *testing.T) {
+	_, leaderAddress := newInstance(t, testSecret)
+
+	header := http.Header{SecretHeader: {testSecret}, "Origin": {"http://evil.example.com"}}
+	conn, resp, err := websocket.DefaultDialer.Dial("ws://"+leaderAddress+"/party?party=Leader&name=Browser", header)
+	if err == nil {
+		conn.Close()
+		t.Fatal("Expected connection from another origin to be rejected")
+	}
+	if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected forbidden response, got %v", resp)
+	}
+}
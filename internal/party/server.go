@@ -0,0 +1,157 @@
+package party
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const writeTimeout = 10 * time.Second
+
+// SecretHeader carries the party secret, connections without the secret of the leader instance are rejected
+const SecretHeader = "X-Party-Secret"
+
+// Server is served by the leader koolo instance, followers from other machines connect to it to receive the leader
+// messages and to let the leader know about their state
+type Server struct {
+	logger   *slog.Logger
+	secret   func() string
+	upgrader websocket.Upgrader
+	mu       sync.Mutex
+	peers    map[*peer]struct{}
+	received chan Message
+}
+
+type peer struct {
+	conn  *websocket.Conn
+	party string
+	name  string
+	send  chan Message
+}
+
+// NewServer returns a party server, secret is read on every connection so config changes are applied without restarts.
+// Browsers are only allowed from the same origin, followers don't send the origin header.
+func NewServer(logger *slog.Logger, secret func() string) *Server {
+	return &Server{
+		logger:   logger,
+		secret:   secret,
+		upgrader: websocket.Upgrader{},
+		peers:    make(map[*peer]struct{}),
+		received: make(chan Message, 100),
+	}
+}
+
+// ServeHTTP upgrades the connection, party and name query parameters and the party secret are required
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secret := s.secret()
+	if secret == "" {
+		s.logger.Warn("Party connection rejected, party secret is not set", slog.String("address", r.RemoteAddr))
+		http.Error(w, "party secret is not set", http.StatusForbidden)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(SecretHeader)), []byte(secret)) != 1 {
+		s.logger.Warn("Party connection rejected, invalid secret", slog.String("address", r.RemoteAddr))
+		http.Error(w, "invalid party secret", http.StatusUnauthorized)
+		return
+	}
+
+	partyName := r.URL.Query().Get("party")
+	name := r.URL.Query().Get("name")
+	if partyName == "" || name == "" {
+		http.Error(w, "party and name are required", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Error("Error upgrading party connection", slog.Any("error", err))
+		return
+	}
+
+	p := &peer{conn: conn, party: partyName, name: name, send: make(chan Message, 100)}
+	s.mu.Lock()
+	s.peers[p] = struct{}{}
+	s.mu.Unlock()
+	s.logger.Info("Party member connected", slog.String("party", partyName), slog.String("name", name), slog.String("address", r.RemoteAddr))
+
+	go s.writePump(p)
+	s.readPump(p)
+}
+
+// Publish sends the message to all the members of the party, the party can be the leader supervisor or character name
+func (s *Server) Publish(msg Message) {
+	if msg.SentAt.IsZero() {
+		msg.SentAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for p := range s.peers {
+		if !strings.EqualFold(p.party, msg.Party) && !strings.EqualFold(p.party, msg.From) {
+			continue
+		}
+
+		select {
+		case p.send <- msg:
+		default:
+			s.logger.Warn("Party member is not reading messages, dropping message", slog.String("name", p.name))
+		}
+	}
+}
+
+// Messages returns the messages sent by the party members
+func (s *Server) Messages() <-chan Message {
+	return s.received
+}
+
+// Close disconnects all the party members
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for p := range s.peers {
+		p.conn.Close()
+	}
+}
+
+func (s *Server) readPump(p *peer) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.peers, p)
+		close(p.send)
+		s.mu.Unlock()
+		p.conn.Close()
+		s.logger.Info("Party member disconnected", slog.String("party", p.party), slog.String("name", p.name))
+	}()
+
+	for {
+		var msg Message
+		if err := p.conn.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		// Don't trust the client, the connection already knows who it is
+		msg.Party = p.party
+		msg.From = p.name
+		select {
+		case s.received <- msg:
+		default:
+		}
+	}
+}
+
+func (s *Server) writePump(p *peer) {
+	for msg := range p.send {
+		p.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := p.conn.WriteJSON(msg); err != nil {
+			p.conn.Close()
+			return
+		}
+	}
+}
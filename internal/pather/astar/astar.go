@@ -69,7 +69,7 @@ func CalculatePath(g *game.Grid, start, goal data.Position) ([]data.Position, in
 		updateNeighbors(g, current, &neighbors)
 
 		for _, neighbor := range neighbors {
-			newCost := costSoFar[current.X][current.Y] + getCost(g.CollisionGrid[neighbor.Y][neighbor.X])
+			newCost := costSoFar[current.X][current.Y] + getCost(g.CollisionGrid[neighbor.Y][neighbor.X]) + g.ThreatCost(neighbor)
 
 			// Handicap for changing direction, this prevents zig-zagging around obstacles
 			//curDirX, curDirY := direction(cameFrom[current.X][current.Y], current.Position)
@@ -0,0 +1,109 @@
+package pather
+
+import (
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// Coverage keeps track of the walkable tiles of the current area the player already had in sight
+type Coverage struct {
+	pf       *PathFinder
+	grid     *game.Grid
+	explored [][]bool
+	walkable int
+	seen     int
+}
+
+func (pf *PathFinder) NewCoverage() *Coverage {
+	grid := pf.data.AreaData.Grid
+
+	explored := make([][]bool, grid.Height)
+	walkable := 0
+	for y := 0; y < grid.Height; y++ {
+		explored[y] = make([]bool, grid.Width)
+		for x := 0; x < grid.Width; x++ {
+			if grid.CollisionGrid[y][x] != game.CollisionTypeNonWalkable {
+				walkable++
+			}
+		}
+	}
+
+	return &Coverage{
+		pf:       pf,
+		grid:     grid,
+		explored: explored,
+		walkable: walkable,
+	}
+}
+
+// MarkExplored marks as explored all the walkable tiles in line of sight inside the radius
+func (c *Coverage) MarkExplored(pos data.Position, radius int) {
+	center := c.grid.RelativePosition(pos)
+	for y := max(center.Y-radius, 0); y <= min(center.Y+radius, c.grid.Height-1); y++ {
+		for x := max(center.X-radius, 0); x <= min(center.X+radius, c.grid.Width-1); x++ {
+			if c.explored[y][x] || c.grid.CollisionGrid[y][x] == game.CollisionTypeNonWalkable {
+				continue
+			}
+
+			tile := data.Position{X: x + c.grid.OffsetX, Y: y + c.grid.OffsetY}
+			if DistanceFromPoint(pos, tile) > radius || !c.pf.LineOfSight(pos, tile) {
+				continue
+			}
+
+			c.explored[y][x] = true
+			c.seen++
+		}
+	}
+}
+
+// RoomCoverage returns the percent of walkable tiles of the room already explored
+func (c *Coverage) RoomCoverage(room data.Room) float64 {
+	walkable, seen := 0, 0
+	for y := room.Y; y < room.Y+room.Height; y++ {
+		for x := room.X; x < room.X+room.Width; x++ {
+			rel := c.grid.RelativePosition(data.Position{X: x, Y: y})
+			if rel.X < 0 || rel.X >= c.grid.Width || rel.Y < 0 || rel.Y >= c.grid.Height {
+				continue
+			}
+			if c.grid.CollisionGrid[rel.Y][rel.X] == game.CollisionTypeNonWalkable {
+				continue
+			}
+			walkable++
+			if c.explored[rel.Y][rel.X] {
+				seen++
+			}
+		}
+	}
+
+	if walkable == 0 {
+		return 100
+	}
+
+	return float64(seen) * 100 / float64(walkable)
+}
+
+// Percent returns the percent of walkable tiles of the area already explored
+func (c *Coverage) Percent() float64 {
+	if c.walkable == 0 {
+		return 100
+	}
+
+	return float64(c.seen) * 100 / float64(c.walkable)
+}
+
+// IsDeadEnd returns true if the room is only connected to a single room, so we need to walk back after visiting it
+func (pf *PathFinder) IsDeadEnd(room data.Room) bool {
+	connections := 0
+	for _, r := range pf.data.Rooms {
+		if r == room {
+			continue
+		}
+
+		// Rooms are connected if they are touching each other
+		if r.X <= room.X+room.Width && room.X <= r.X+r.Width && r.Y <= room.Y+room.Height && room.Y <= r.Y+r.Height {
+			connections++
+		}
+	}
+
+	return connections == 1
+}
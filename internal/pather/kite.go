@@ -0,0 +1,75 @@
+package pather
+
+import (
+	"math"
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+)
+
+const kiteDirections = 16
+
+// rangedMonsters attack from the distance, moving away from them doesn't help so they never trigger kiting
+var rangedMonsters = []npc.ID{
+	npc.DarkRanger, npc.VileArcher, npc.VileArcher2, npc.DarkArcher, npc.DarkArcher2, npc.DarkArcher3, npc.BlackArcher, npc.FleshArcher,
+	npc.SkeletonArcher, npc.ReturnedArcher, npc.ReturnedArcher2, npc.BoneArcher, npc.BoneArcher2, npc.BurningDeadArcher,
+	npc.BurningDeadArcher2, npc.BurningDeadArcher3, npc.HorrorArcher, npc.HorrorArcher2, npc.HorrorArcher3,
+	npc.FallenShaman, npc.CarverShaman, npc.CarverShaman2, npc.DevilkinShaman, npc.DevilkinShaman2, npc.DarkShaman,
+	npc.DarkShaman2, npc.WarpedShaman, npc.FetishShaman, npc.FlayerShaman, npc.FlayerShaman2, npc.SoulKillerShaman,
+	npc.SoulKillerShaman2, npc.StygianDollShaman, npc.StygianDollShaman2, npc.RatManShaman,
+	npc.VileTemptress, npc.StygianHarlot, npc.HellTemptress, npc.HellTemptress2, npc.HellTemptress3, npc.BloodTemptress,
+	npc.Dominus, npc.VileWitch, npc.VileWitch2, npc.VileWitch3, npc.StygianFury, npc.BloodWitch, npc.HellWitch, npc.HellWitch2,
+	npc.Slinger, npc.SpearCat, npc.HellSlinger, npc.CorpseSpitter, npc.Imp, npc.FireTower,
+}
+
+// IsMeleeAttacker returns false for the monsters attacking from the distance, the ones kiting is not useful against
+func IsMeleeAttacker(m data.Monster) bool {
+	return !slices.Contains(rangedMonsters, m.Name)
+}
+
+// KitePosition returns a walkable position where all the given enemies are at least at minDistance, trying to stay
+// closer than maxDistance from the nearest one, so we can keep attacking. Positions behind walls are discarded.
+func (pf *PathFinder) KitePosition(enemies []data.Position, minDistance, maxDistance int) (data.Position, bool) {
+	playerPos := pf.data.PlayerUnit.Position
+
+	threats := pf.Threats()
+	bestPos := data.Position{}
+	bestScore := math.MinInt32
+	found := false
+	for step := minDistance / 2; step <= maxDistance; step += 2 {
+		for i := 0; i < kiteDirections; i++ {
+			angle := 2 * math.Pi * float64(i) / kiteDirections
+			pos := data.Position{
+				X: playerPos.X + int(math.Round(float64(step)*math.Cos(angle))),
+				Y: playerPos.Y + int(math.Round(float64(step)*math.Sin(angle))),
+			}
+
+			if !pf.data.AreaData.IsWalkable(pos) || !pf.LineOfSight(playerPos, pos) {
+				continue
+			}
+
+			closest := math.MaxInt32
+			for _, e := range enemies {
+				closest = min(closest, DistanceFromPoint(pos, e))
+			}
+			if closest < minDistance {
+				continue
+			}
+
+			// Prefer positions inside the distance band, with less threat around and with a shorter movement
+			score := -threats.At(pos) - step
+			if closest > maxDistance {
+				score -= (closest - maxDistance) * 2
+			}
+
+			if !found || score > bestScore {
+				bestPos = pos
+				bestScore = score
+				found = true
+			}
+		}
+	}
+
+	return bestPos, found
+}
@@ -0,0 +1,79 @@
+package pather
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// kiteTestPathFinder returns a path finder for an open 41x41 area with the player in the middle, walls can be added
+// setting the tiles to non walkable
+func kiteTestPathFinder(walls func(x, y int) bool) *PathFinder {
+	collisionGrid := make([][]game.CollisionType, 41)
+	for y := range collisionGrid {
+		collisionGrid[y] = make([]game.CollisionType, 41)
+		for x := range collisionGrid[y] {
+			collisionGrid[y][x] = game.CollisionTypeWalkable
+			if walls != nil && walls(x, y) {
+				collisionGrid[y][x] = game.CollisionTypeNonWalkable
+			}
+		}
+	}
+
+	d := &game.Data{AreaData: game.AreaData{Grid: game.NewGrid(collisionGrid, 0, 0)}}
+	d.PlayerUnit.Position = data.Position{X: 20, Y: 20}
+
+	return &PathFinder{data: d}
+}
+
+func TestKitePosition(t *testing.T) {
+	enemy := data.Position{X: 17, Y: 20}
+	pf := kiteTestPathFinder(nil)
+
+	pos, found := pf.KitePosition([]data.Position{enemy}, 8, 15)
+	if !found {
+		t.Fatal("expected a kite position")
+	}
+	if distance := DistanceFromPoint(pos, enemy); distance < 8 || distance > 15 {
+		t.Errorf("expected the enemy between 8 and 15 from %v, got %d", pos, distance)
+	}
+	if pos.X <= 20 {
+		t.Errorf("expected to move away from the enemy, got %v", pos)
+	}
+}
+
+func TestKitePositionRespectsWalls(t *testing.T) {
+	enemy := data.Position{X: 17, Y: 20}
+	pf := kiteTestPathFinder(func(x, y int) bool { return x == 23 })
+
+	pos, found := pf.KitePosition([]data.Position{enemy}, 8, 15)
+	if !found {
+		t.Fatal("expected a kite position")
+	}
+	if pos.X >= 23 {
+		t.Errorf("kite position %v is behind the wall", pos)
+	}
+	if distance := DistanceFromPoint(pos, enemy); distance < 8 {
+		t.Errorf("expected the enemy at least at 8 from %v, got %d", pos, distance)
+	}
+}
+
+func TestKitePositionNotFound(t *testing.T) {
+	// Small room around the player
+	pf := kiteTestPathFinder(func(x, y int) bool { return x < 18 || x > 22 || y < 18 || y > 22 })
+
+	if pos, found := pf.KitePosition([]data.Position{{X: 19, Y: 20}}, 8, 15); found {
+		t.Errorf("expected no kite position inside the room, got %v", pos)
+	}
+}
+
+func TestIsMeleeAttacker(t *testing.T) {
+	if !IsMeleeAttacker(data.Monster{Name: npc.Zombie}) {
+		t.Error("zombies should be melee attackers")
+	}
+	if IsMeleeAttacker(data.Monster{Name: npc.DarkArcher}) || IsMeleeAttacker(data.Monster{Name: npc.FallenShaman}) {
+		t.Error("archers and shamans should not be melee attackers")
+	}
+}
@@ -106,6 +106,11 @@ func (pf *PathFinder) GetPathFrom(from, to data.Position) (Path, int, bool) {
 		grid.CollisionGrid[relativePos.Y][relativePos.X] = game.CollisionTypeMonster
 	}
 
+	// Increase the cost around dangerous monsters, so we prefer safer routes even if they are a bit longer
+	for _, t := range pf.Threats() {
+		grid.AddThreat(grid.RelativePosition(t.position), t.radius, t.weight)
+	}
+
 	path, distance, found := astar.CalculatePath(grid, from, to)
 
 	if config.Koolo.Debug.RenderMap {
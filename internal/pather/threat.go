@@ -0,0 +1,84 @@
+package pather
+
+import (
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/data/state"
+	"github.com/hectorgimenez/d2go/pkg/utils"
+)
+
+type threat struct {
+	position data.Position
+	radius   int
+	weight   int
+}
+
+// Threats is the list of dangerous areas around the enemies, it's built once and reused for every position we evaluate
+type Threats []threat
+
+func (pf *PathFinder) Threats() Threats {
+	return threatsFrom(pf.data.Monsters.Enemies())
+}
+
+func threatsFrom(enemies []data.Monster) Threats {
+	threats := make(Threats, 0)
+	for _, m := range enemies {
+		if radius, weight, found := monsterThreat(m); found {
+			threats = append(threats, threat{position: m.Position, radius: radius, weight: weight})
+		}
+	}
+
+	return threats
+}
+
+// At returns the accumulated threat cost of standing at the given position, 0 means it's safe
+func (t Threats) At(pos data.Position) int {
+	cost := 0
+	for _, th := range t {
+		distance := utils.DistanceFromPoint(th.position, pos)
+		if distance <= th.radius {
+			cost += th.weight * (th.radius - distance + 1) / (th.radius + 1)
+		}
+	}
+
+	return cost
+}
+
+// monsterThreat returns the radius and the weight of the dangerous area around the monster.
+// d2go doesn't expose monster enchantments, so for elites we guess them from the resistances the enchantment adds,
+// it's not perfect but false positives only make the path a bit longer.
+func monsterThreat(m data.Monster) (radius int, weight int, found bool) {
+	switch m.Name {
+	// Dolls explode on death
+	case npc.StygianDoll, npc.StygianDoll2, npc.StygianDoll3, npc.StygianDoll4, npc.UndeadStygianDoll, npc.UndeadStygianDoll2:
+		return 6, 30, true
+	// Souls have a long range lightning attack
+	case npc.BurningSoul, npc.BurningSoul2, npc.BurningSoul3, npc.BlackSoul, npc.BlackSoul2:
+		return 10, 20, true
+	case npc.CouncilMember, npc.CouncilMember2, npc.CouncilMember3:
+		return 8, 15, true
+	}
+
+	if m.Type != data.MonsterTypeUnique && m.Type != data.MonsterTypeSuperUnique {
+		return 0, 0, false
+	}
+
+	switch {
+	case m.States.HasState(state.Conviction):
+		return 12, 25, true
+	case m.States.HasState(state.Fanaticism), m.States.HasState(state.Might):
+		return 8, 15, true
+	// Lightning Enchanted
+	case m.Stats[stat.LightningResist] >= 100:
+		return 8, 20, true
+	// Fire Enchanted, explodes on death
+	case m.Stats[stat.FireResist] >= 100:
+		return 5, 20, true
+	// Mana Burn
+	case m.Stats[stat.MagicResist] >= 20:
+		return 5, 10, true
+	}
+
+	return 0, 0, false
+}
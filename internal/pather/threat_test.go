@@ -0,0 +1,58 @@
+package pather
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+)
+
+func TestThreatsFrom(t *testing.T) {
+	enemies := []data.Monster{
+		{Name: npc.StygianDoll, Position: data.Position{X: 10, Y: 10}, Type: data.MonsterTypeNone},
+		{Name: npc.Zombie, Position: data.Position{X: 20, Y: 20}, Type: data.MonsterTypeNone},
+		// Lightning enchanted unique
+		{Name: npc.Zombie, Position: data.Position{X: 30, Y: 30}, Type: data.MonsterTypeUnique, Stats: map[stat.ID]int{stat.LightningResist: 100}},
+		// Same resistances on a normal monster are not an enchantment
+		{Name: npc.Zombie, Position: data.Position{X: 40, Y: 40}, Type: data.MonsterTypeNone, Stats: map[stat.ID]int{stat.LightningResist: 100}},
+	}
+
+	threats := threatsFrom(enemies)
+	if len(threats) != 2 {
+		t.Fatalf("expected 2 threats, got %d", len(threats))
+	}
+	if threats[0].position != (data.Position{X: 10, Y: 10}) || threats[0].radius != 6 || threats[0].weight != 30 {
+		t.Errorf("unexpected doll threat %+v", threats[0])
+	}
+	if threats[1].position != (data.Position{X: 30, Y: 30}) || threats[1].radius != 8 || threats[1].weight != 20 {
+		t.Errorf("unexpected lightning enchanted threat %+v", threats[1])
+	}
+}
+
+func TestThreatsAt(t *testing.T) {
+	threats := Threats{
+		{position: data.Position{X: 10, Y: 10}, radius: 6, weight: 30},
+		{position: data.Position{X: 15, Y: 10}, radius: 4, weight: 10},
+	}
+
+	tests := []struct {
+		name     string
+		pos      data.Position
+		expected int
+	}{
+		{name: "center of the first threat", pos: data.Position{X: 10, Y: 10}, expected: 30},
+		{name: "overlapping threats", pos: data.Position{X: 13, Y: 10}, expected: 30*4/7 + 10*3/5},
+		{name: "edge of the first threat", pos: data.Position{X: 10, Y: 16}, expected: 30 * 1 / 7},
+		{name: "safe position", pos: data.Position{X: 30, Y: 30}, expected: 0},
+	}
+	for _, tt := range tests {
+		if cost := threats.At(tt.pos); cost != tt.expected {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.expected, cost)
+		}
+	}
+
+	if cost := (Threats{}).At(data.Position{X: 10, Y: 10}); cost != 0 {
+		t.Errorf("expected 0 without threats, got %d", cost)
+	}
+}
@@ -69,6 +69,34 @@ func (pf *PathFinder) OptimizeRoomsTraverseOrder() []data.Room {
 		currentRoom = nextRoom
 	}
 
+	return twoOpt(order, distanceMatrix)
+}
+
+// twoOpt improves the nearest neighbor route by reversing segments while the total distance keeps decreasing,
+// first room is where the player is, so it's never moved. We don't come back to it, so the last edge is not counted.
+func twoOpt(order []data.Room, distanceMatrix map[data.Room]map[data.Room]int) []data.Room {
+	improved := true
+	for improved {
+		improved = false
+		for i := 1; i < len(order)-1; i++ {
+			for j := i + 1; j < len(order); j++ {
+				before := distanceMatrix[order[i-1]][order[i]]
+				after := distanceMatrix[order[i-1]][order[j]]
+				if j+1 < len(order) {
+					before += distanceMatrix[order[j]][order[j+1]]
+					after += distanceMatrix[order[i]][order[j+1]]
+				}
+
+				if after < before {
+					for l, r := i, j; l < r; l, r = l+1, r-1 {
+						order[l], order[r] = order[r], order[l]
+					}
+					improved = true
+				}
+			}
+		}
+	}
+
 	return order
 }
 
@@ -168,6 +168,11 @@ func (b *Bot) handleStatsRequest(s *discordgo.Session, m *discordgo.MessageCreat
 						Value:  fmt.Sprintf("%d", b.manager.GetSupervisorStats(supervisor).TotalErrors()),
 						Inline: true,
 					},
+					{
+						Name:   "Retries",
+						Value:  fmt.Sprintf("%d", b.manager.GetSupervisorStats(supervisor).TotalRetries()),
+						Inline: true,
+					},
 				},
 			}
 
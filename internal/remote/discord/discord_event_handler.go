@@ -19,13 +19,19 @@ func (b *Bot) Handle(_ context.Context, e event.Event) error {
 			message := fmt.Sprintf("%s\nGame: %s\nPassword: %s", evt.Message(), evt.Name, evt.Password)
 			_, err := b.discordSession.ChannelMessageSend(b.channelID, message)
 			return err
-		case event.GameFinishedEvent, event.RunStartedEvent, event.RunFinishedEvent:
+		case event.GameFinishedEvent, event.RunStartedEvent, event.RunFinishedEvent, event.OnlineFailureEvent:
 			_, err := b.discordSession.ChannelMessageSend(b.channelID, e.Message())
 			return err
 		default:
 			break
 		}
 
+		// Crash loop and stuck events may not have a screenshot, the client could be closed or not responding
+		if e.Image() == nil {
+			_, err := b.discordSession.ChannelMessageSend(b.channelID, e.Message())
+			return err
+		}
+
 		buf := new(bytes.Buffer)
 		err := jpeg.Encode(buf, e.Image(), &jpeg.Options{Quality: 80})
 		if err != nil {
@@ -63,6 +69,8 @@ func (b *Bot) shouldPublish(e event.Event) bool {
 		return config.Koolo.Discord.EnableNewRunMessages
 	case event.RunFinishedEvent:
 		return config.Koolo.Discord.EnableRunFinishMessages
+	case event.OnlineFailureEvent, event.SupervisorCrashLoopEvent, event.StuckDetectedEvent:
+		return config.Koolo.Discord.EnableDiscordErrorMessages
 	default:
 		break
 	}
@@ -0,0 +1,178 @@
+package run
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/action"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather"
+	"github.com/hectorgimenez/koolo/internal/town"
+	"github.com/hectorgimenez/koolo/internal/utils"
+)
+
+const (
+	// Monsters closer than this to the leader are the ones the leader is fighting
+	companionAttackRadius = 15
+	// Leader is not in the party roster anymore, it left the game or it never joined it
+	companionLeaderTimeout = time.Minute
+)
+
+// Companion follows the leader through its portals until the leader leaves the game. Followers don't run their own
+// scripts, they assist the leader killing the monsters around it.
+type Companion struct {
+	ctx           *context.Status
+	lastPartyBuff time.Time
+}
+
+func NewCompanion() *Companion {
+	return &Companion{
+		ctx: context.Get(),
+	}
+}
+
+func (c Companion) Name() string {
+	return string(config.CompanionRun)
+}
+
+func (c *Companion) Run() error {
+	leaderName := c.ctx.CharacterCfg.Companion.LeaderName
+	if leaderName == "" {
+		return errors.New("leader name is required for companion mode")
+	}
+
+	leaderSeenAt := time.Now()
+	for {
+		c.ctx.PauseIfNotPriority()
+
+		leader, found := c.ctx.Data.Roster.FindByName(leaderName)
+		if !found {
+			if time.Since(leaderSeenAt) > companionLeaderTimeout {
+				c.ctx.Logger.Info("Leader is not in the game, finishing companion run", slog.String("leader", leaderName))
+				return nil
+			}
+			utils.Sleep(500)
+			continue
+		}
+		leaderSeenAt = time.Now()
+
+		if c.ctx.Data.PlayerUnit.Area.IsTown() {
+			if leader.Area.IsTown() {
+				utils.Sleep(500)
+				continue
+			}
+
+			if err := c.followLeaderTo(leader.Area); err != nil {
+				c.ctx.Logger.Debug("Could not reach the leader area, waiting", slog.Any("error", err))
+				utils.Sleep(500)
+			}
+			continue
+		}
+
+		// Leader went back to town, let's go with it
+		if leader.Area.IsTown() {
+			if err := action.ReturnTown(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Leader moved to another area using the stairs, a waypoint or its own portal
+		if leader.Area != c.ctx.Data.PlayerUnit.Area {
+			if err := c.followLeaderTo(leader.Area); err != nil {
+				c.ctx.Logger.Debug("Could not reach the leader area, waiting", slog.Any("error", err))
+				utils.Sleep(500)
+			}
+			continue
+		}
+
+		distanceToLeader := pather.DistanceFromPoint(c.ctx.Data.PlayerUnit.Position, leader.Position)
+		if c.ctx.CharacterCfg.Companion.FollowLeader && distanceToLeader > c.ctx.CharacterCfg.Companion.FollowDistance {
+			_ = action.MoveToCoords(leader.Position)
+			continue
+		}
+
+		// Buffs like BO or Oak are shared with the party members close to us. Buff skips buffing if it was done less
+		// than 30 seconds ago, the buff interval is the one deciding here
+		if time.Since(c.lastPartyBuff) > time.Duration(c.ctx.CharacterCfg.Companion.BuffInterval)*time.Second {
+			c.ctx.LastBuffAt = time.Time{}
+			action.Buff()
+			c.lastPartyBuff = time.Now()
+		}
+
+		if c.ctx.CharacterCfg.Companion.Attack {
+			if err := c.assistLeader(leaderName); err != nil {
+				c.ctx.Logger.Debug("Error attacking leader targets", slog.Any("error", err))
+			}
+		}
+
+		utils.Sleep(200)
+	}
+}
+
+// assistLeader attacks the monsters around the leader, it returns once there are no more monsters close to it
+func (c *Companion) assistLeader(leaderName string) error {
+	return c.ctx.Char.KillMonsterSequence(func(d game.Data) (data.UnitID, bool) {
+		leader, found := d.Roster.FindByName(leaderName)
+		if !found || leader.Area != d.PlayerUnit.Area {
+			return 0, false
+		}
+
+		monsters := make([]data.Monster, 0)
+		for _, m := range d.Monsters.Enemies() {
+			if pather.DistanceFromPoint(leader.Position, m.Position) <= companionAttackRadius {
+				monsters = append(monsters, m)
+			}
+		}
+
+		if m, found := d.BestTarget(monsters, c.ctx.IsImmuneToCharacter); found {
+			return m.UnitID, true
+		}
+
+		return 0, false
+	}, nil)
+}
+
+// followLeaderTo goes to town and takes the leader portal, if the leader didn't open one the waypoint is used when
+// the area has it
+func (c *Companion) followLeaderTo(leaderArea area.ID) error {
+	if !c.ctx.Data.PlayerUnit.Area.IsTown() {
+		if err := action.ReturnTown(); err != nil {
+			return err
+		}
+	}
+
+	err := c.useLeaderPortal(leaderArea.Act())
+	if _, hasWaypoint := area.WPAddresses[leaderArea]; err == nil || !hasWaypoint {
+		return err
+	}
+
+	c.ctx.Logger.Debug("Leader portal not found, using the waypoint", slog.String("area", area.Areas[leaderArea].Name), slog.Any("error", err))
+
+	return action.WayPoint(leaderArea)
+}
+
+// useLeaderPortal goes to the town where the leader opened the portal and takes it
+func (c *Companion) useLeaderPortal(leaderAct int) error {
+	leaderTown := town.GetTownByArea(c.ctx.Data.PlayerUnit.Area)
+	if c.ctx.Data.PlayerUnit.Area.Act() != leaderAct {
+		for _, t := range []town.Town{town.A1{}, town.A2{}, town.A3{}, town.A4{}, town.A5{}} {
+			if t.TownArea().Act() == leaderAct {
+				leaderTown = t
+			}
+		}
+
+		if err := action.WayPoint(leaderTown.TownArea()); err != nil {
+			return err
+		}
+	}
+
+	_ = action.MoveToCoords(leaderTown.TPWaitingArea(*c.ctx.Data))
+
+	return action.UsePortalFrom(c.ctx.CharacterCfg.Companion.LeaderName)
+}
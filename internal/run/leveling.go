@@ -1,10 +1,22 @@
 package run
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/d2go/pkg/data/quest"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/action"
 	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
 )
 
+var difficultyOrder = []difficulty.Difficulty{difficulty.Normal, difficulty.Nightmare, difficulty.Hell}
+
 type Leveling struct {
 	ctx *context.Status
 }
@@ -20,11 +32,91 @@ func (a Leveling) Name() string {
 }
 
 func (a Leveling) Run() error {
+	if a.isLevelingFinished() {
+		return a.handover()
+	}
+
 	a.act1()
 	a.act2()
 	a.act3()
 	a.act4()
 	a.act5()
 
+	if a.isLevelingFinished() {
+		return a.handover()
+	}
+
+	return nil
+}
+
+// isLevelingFinished checks if the character reached the handover criteria defined in the config
+func (a Leveling) isLevelingFinished() bool {
+	handover := a.ctx.CharacterCfg.Game.Leveling.Handover
+	if !handover.Enabled {
+		return false
+	}
+
+	lvl, _ := a.ctx.Data.PlayerUnit.FindStat(stat.Level, 0)
+	if lvl.Value < handover.Level {
+		return false
+	}
+
+	if handover.Difficulty != "" && slices.Index(difficultyOrder, a.ctx.CharacterCfg.Game.Difficulty) < slices.Index(difficultyOrder, handover.Difficulty) {
+		return false
+	}
+
+	if handover.RequireAct5Done && !a.ctx.Data.Quests[quest.Act5EveOfDestruction].Completed() {
+		return false
+	}
+
+	return true
+}
+
+// handover resets the character at Akara and switches the config to the farming profile, the supervisor will be
+// restarted with the new class and runs after receiving the event
+func (a Leveling) handover() error {
+	handover := a.ctx.CharacterCfg.Game.Leveling.Handover
+
+	class := handover.Class
+	if class == "" && a.ctx.CharacterCfg.Runtime.LevelingBuild != nil {
+		class = a.ctx.CharacterCfg.Runtime.LevelingBuild.EndGameClass
+	}
+	if class == "" {
+		return errors.New("leveling finished but there is no class to switch to, set leveling handover class")
+	}
+	if len(handover.Runs) == 0 {
+		return errors.New("leveling finished but there are no runs to switch to, set leveling handover runs")
+	}
+
+	a.ctx.Logger.Info("Leveling finished, switching to farming profile", slog.String("class", class), slog.Any("runs", handover.Runs))
+
+	if err := action.RespecAtAkara(); err != nil {
+		return fmt.Errorf("error resetting character: %w", err)
+	}
+
+	// Points are spent following the last section of the leveling build, farming classes don't allocate points
+	a.ctx.RefreshGameData()
+	if err := action.EnsureStatPoints(); err != nil {
+		return fmt.Errorf("error allocating stat points after respec: %w", err)
+	}
+	if err := action.EnsureSkillPoints(); err != nil {
+		return fmt.Errorf("error allocating skill points after respec: %w", err)
+	}
+
+	cfg := *a.ctx.CharacterCfg
+	cfg.Character.Class = class
+	cfg.Character.LevelingBuild = ""
+	cfg.Game.Runs = slices.Clone(handover.Runs)
+	cfg.Game.Leveling.Handover.Enabled = false
+	if err := config.SaveSupervisorConfig(a.ctx.Name, &cfg); err != nil {
+		return fmt.Errorf("error saving farming profile: %w", err)
+	}
+
+	runs := make([]string, 0, len(cfg.Game.Runs))
+	for _, r := range cfg.Game.Runs {
+		runs = append(runs, string(r))
+	}
+	event.Send(event.LevelingFinished(event.Text(a.ctx.Name, fmt.Sprintf("Leveling finished, switching to %s", class)), class, runs))
+
 	return nil
 }
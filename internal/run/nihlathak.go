@@ -71,12 +71,17 @@ func (n Nihlathak) Run() error {
 		n.ctx.Logger.Debug("Clearing monsters around Nihlathak position")
 
 		n.ctx.Char.KillMonsterSequence(func(d game.Data) (data.UnitID, bool) {
+			monsters := make([]data.Monster, 0)
 			for _, m := range d.Monsters.Enemies() {
-				if d := pather.DistanceFromPoint(nihlaObject.Position, m.Position); d < 15 {
-					return m.UnitID, true
+				if pather.DistanceFromPoint(nihlaObject.Position, m.Position) < 15 {
+					monsters = append(monsters, m)
 				}
 			}
 
+			if m, found := d.BestTarget(monsters, n.ctx.IsImmuneToCharacter); found {
+				return m.UnitID, true
+			}
+
 			return 0, false
 		}, nil)
 	}
@@ -10,9 +10,10 @@ type Run interface {
 }
 
 func BuildRuns(cfg *config.CharacterCfg) (runs []Run) {
-	//if cfg.Companion.Enabled && !cfg.Companion.Leader {
-	//	return []Run{Companion{baseRun: baseRun}}
-	//}
+	// Followers don't have their own runs, they just follow the leader
+	if cfg.Companion.Enabled && !cfg.Companion.Leader {
+		return []Run{NewCompanion()}
+	}
 
 	for _, run := range cfg.Game.Runs {
 		// Prepend terror zone runs, we want to run it always first
@@ -85,6 +86,8 @@ func BuildRuns(cfg *config.CharacterCfg) (runs []Run) {
 			runs = append(runs, NewDriverCavern())
 		case config.EnduguRun:
 			runs = append(runs, NewEndugu())
+		case config.ShoppingRun:
+			runs = append(runs, NewShopping())
 		}
 	}
 
@@ -0,0 +1,117 @@
+package run
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/koolo/internal/action"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/context"
+)
+
+type shoppingVendor struct {
+	npc  npc.ID
+	town area.ID
+}
+
+var shoppingVendors = map[string]shoppingVendor{
+	"anya":    {npc: npc.Drehya, town: area.Harrogath},
+	"drognan": {npc: npc.Drognan, town: area.LutGholein},
+	"ormus":   {npc: npc.Ormus, town: area.KurastDocks},
+	"fara":    {npc: npc.Fara, town: area.LutGholein},
+}
+
+type Shopping struct {
+	ctx *context.Status
+}
+
+func NewShopping() *Shopping {
+	return &Shopping{
+		ctx: context.Get(),
+	}
+}
+
+func (s Shopping) Name() string {
+	return string(config.ShoppingRun)
+}
+
+func (s Shopping) Run() error {
+	if len(s.ctx.CharacterCfg.Runtime.ShoppingRules) == 0 {
+		return errors.New("shopping rules not found, add them to the shopping directory of the character config")
+	}
+
+	vendors := make([]shoppingVendor, 0)
+	for _, name := range s.ctx.CharacterCfg.Game.Shopping.Vendors {
+		vendor, found := shoppingVendors[name]
+		if !found {
+			s.ctx.Logger.Warn("Unknown shopping vendor, skipping it", slog.String("vendor", name))
+			continue
+		}
+		vendors = append(vendors, vendor)
+	}
+
+	if len(vendors) == 0 {
+		return errors.New("no valid vendors configured for shopping")
+	}
+
+	// Vendor items are only refreshed after leaving the town, so we keep track of the vendors already checked since
+	// the last time we changed the area
+	visited := make(map[npc.ID]bool)
+	totalBought := 0
+	for i := 0; i < s.ctx.CharacterCfg.Game.Shopping.Iterations; i++ {
+		for _, vendor := range vendors {
+			s.ctx.PauseIfNotPriority()
+
+			if visited[vendor.npc] {
+				if err := s.refreshVendors(vendor.town); err != nil {
+					return err
+				}
+				clear(visited)
+			}
+
+			if s.ctx.Data.PlayerUnit.Area != vendor.town {
+				if err := action.WayPoint(vendor.town); err != nil {
+					return err
+				}
+				clear(visited)
+			}
+
+			bought, err := action.ShopAtVendor(vendor.npc)
+			visited[vendor.npc] = true
+			if err != nil {
+				s.ctx.Logger.Warn("Failed shopping at vendor", slog.Any("error", err))
+				continue
+			}
+
+			if bought > 0 {
+				totalBought += bought
+				action.Stash(false)
+			}
+
+			if s.ctx.Data.PlayerUnit.TotalPlayerGold() < s.ctx.CharacterCfg.Game.Shopping.MinGoldReserve {
+				s.ctx.Logger.Info("Gold reserve reached, finishing shopping", slog.Int("itemsBought", totalBought))
+				return nil
+			}
+		}
+	}
+
+	s.ctx.Logger.Info("Shopping finished", slog.Int("itemsBought", totalBought))
+
+	return nil
+}
+
+// refreshVendors takes the waypoint to another town and comes back, this way the vendor items are regenerated
+func (s Shopping) refreshVendors(town area.ID) error {
+	otherTown := area.LutGholein
+	if town == area.LutGholein {
+		otherTown = area.KurastDocks
+	}
+
+	if err := action.WayPoint(otherTown); err != nil {
+		return err
+	}
+
+	return action.WayPoint(town)
+}
@@ -131,6 +131,14 @@ let socket;
                         <div class="stat-label">Errors</div>
                         <div class="stat-value errors">0</div>
                     </div>
+                    <div class="stat-item">
+                        <div class="stat-label">Gambling</div>
+                        <div class="stat-value gambling">None</div>
+                    </div>
+                    <div class="stat-item">
+                        <div class="stat-label">Crafting</div>
+                        <div class="stat-value crafting">None</div>
+                    </div>
                 </div>
                 <div class="run-stats"></div>
             </div>
@@ -226,6 +234,8 @@ let socket;
 
 
         updateStats(card, key, value.Games, dropCount);
+        updateGamblingStats(card, value.Gambling);
+        updateCraftingStats(card, value.Crafting);
         updateRunStats(card, value.Games);
         
         if (statusDetails) {
@@ -311,6 +321,43 @@ function updateButtons(startPauseBtn, stopBtn, attachBtn, status) {
     }
 
 
+    function updateGamblingStats(card, gambling) {
+        const gamblingElement = card.querySelector('.gambling');
+        if (!gambling || gambling.ItemsBought === 0) {
+            gamblingElement.textContent = 'None';
+            gamblingElement.removeAttribute('title');
+            return;
+        }
+
+        const qualities = Object.entries(gambling.Qualities || {})
+            .map(([quality, count]) => `${quality}: ${count}`)
+            .join(', ');
+
+        gamblingElement.textContent = `${gambling.ItemsKept}/${gambling.ItemsBought} kept`;
+        gamblingElement.title = `Sessions: ${gambling.Sessions}\nGold spent: ${gambling.GoldSpent.toLocaleString()}\nNet gold spent: ${gambling.NetGoldSpent.toLocaleString()}\n${qualities}`;
+    }
+
+    function updateCraftingStats(card, crafting) {
+        const craftingElement = card.querySelector('.crafting');
+        const recipes = Object.entries(crafting || {});
+        if (recipes.length === 0) {
+            craftingElement.textContent = 'None';
+            craftingElement.removeAttribute('title');
+            return;
+        }
+
+        const totals = recipes.reduce((acc, [, stats]) => {
+            acc.outputs += stats.Outputs;
+            acc.keepers += stats.Keepers;
+            return acc;
+        }, { outputs: 0, keepers: 0 });
+
+        craftingElement.textContent = `${totals.keepers}/${totals.outputs} kept`;
+        craftingElement.title = recipes
+            .map(([recipe, stats]) => `${recipe}: ${stats.Transmutes} transmutes, ${stats.InputsConsumed} inputs, ${stats.Keepers}/${stats.Outputs} kept`)
+            .join('\n');
+    }
+
     function updateRunStats(card, games) {
     const runStats = calculateRunStats(games);
     const runStatsElement = card.querySelector('.run-stats');
@@ -404,7 +451,7 @@ function updateButtons(startPauseBtn, stopBtn, attachBtn, status) {
                         }
                     }
 
-                    if (run.Reason == 'error') {
+                    if (run.Reason == 'error' || run.Reason == 'timeout') {
                         runStats[run.Name].errorCount++;
                     }
 
@@ -422,6 +422,7 @@ func (s *HttpServer) Listen(port int) error {
 	http.HandleFunc("/ws", s.wsServer.HandleWebSocket)    // Web socket
 	http.HandleFunc("/initial-data", s.initialData)       // Web socket data
 	http.HandleFunc("/api/reload-config", s.reloadConfig) // New handler
+	http.Handle("/party", s.manager.PartyServer())        // Companions running in other machines
 
 	assets, _ := fs.Sub(assetsFS, "assets")
 	http.Handle("/assets/", http.StripPrefix("/assets/", http.FileServer(http.FS(assets))))
@@ -675,6 +676,8 @@ func (s *HttpServer) config(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		newConfig.Telegram.ChatID = telegramChatId
+		// Companions running in other machines
+		newConfig.Party.Secret = r.Form.Get("party_secret")
 
 		err = config.ValidateAndSaveConfig(newConfig)
 		if err != nil {
@@ -854,6 +857,29 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 
 		// Game
 		cfg.Game.CreateLobbyGames = r.Form.Has("createLobbyGames")
+		cfg.BattleNet.MaxFailures, _ = strconv.Atoi(r.Form.Get("battleNetMaxFailures"))
+		cfg.BattleNet.BackoffBase, _ = strconv.Atoi(r.Form.Get("battleNetBackoffBase"))
+		cfg.BattleNet.BackoffMax, _ = strconv.Atoi(r.Form.Get("battleNetBackoffMax"))
+		cfg.Restart.MaxRestarts, _ = strconv.Atoi(r.Form.Get("restartMaxRestarts"))
+		cfg.Restart.WindowMinutes, _ = strconv.Atoi(r.Form.Get("restartWindowMinutes"))
+		cfg.Restart.BackoffBase, _ = strconv.Atoi(r.Form.Get("restartBackoffBase"))
+		cfg.Restart.BackoffMax, _ = strconv.Atoi(r.Form.Get("restartBackoffMax"))
+		cfg.Restart.GiveUpAfter, _ = strconv.Atoi(r.Form.Get("restartGiveUpAfter"))
+		cfg.Watchdog.Enabled = r.Form.Has("watchdogEnabled")
+		cfg.Watchdog.StallSeconds, _ = strconv.Atoi(r.Form.Get("watchdogStallSeconds"))
+		cfg.Game.JoinGames.Enabled = r.Form.Has("joinGamesEnabled")
+		cfg.Game.JoinGames.NamePattern = r.Form.Get("joinGamesNamePattern")
+		cfg.Game.JoinGames.Difficulty = difficulty.Difficulty(r.Form.Get("joinGamesDifficulty"))
+		cfg.Game.JoinGames.MinPlayers, _ = strconv.Atoi(r.Form.Get("joinGamesMinPlayers"))
+		cfg.Game.JoinGames.MaxPlayers, _ = strconv.Atoi(r.Form.Get("joinGamesMaxPlayers"))
+		cfg.Game.JoinGames.MaxLevelRequirement, _ = strconv.Atoi(r.Form.Get("joinGamesMaxLevelRequirement"))
+		cfg.Game.JoinGames.BlacklistMinutes, _ = strconv.Atoi(r.Form.Get("joinGamesBlacklistMinutes"))
+		cfg.Game.JoinGames.Runs = make([]config.Run, 0)
+		for _, run := range strings.Split(r.Form.Get("joinGamesRuns"), ",") {
+			if run = strings.TrimSpace(run); run != "" {
+				cfg.Game.JoinGames.Runs = append(cfg.Game.JoinGames.Runs, config.Run(run))
+			}
+		}
 		cfg.Game.MinGoldPickupThreshold, _ = strconv.Atoi(r.Form.Get("gameMinGoldPickupThreshold"))
 		cfg.UseCentralizedPickit = r.Form.Has("useCentralizedPickit")
 		cfg.Game.UseCainIdentify = r.Form.Has("useCainIdentify")
@@ -961,6 +987,10 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 
 		// Gambling
 		cfg.Gambling.Enabled = r.Form.Has("gamblingEnabled")
+		cfg.Gambling.StartGold, _ = strconv.Atoi(r.Form.Get("gamblingStartGold"))
+		cfg.Gambling.StopGold, _ = strconv.Atoi(r.Form.Get("gamblingStopGold"))
+		cfg.Gambling.SessionBudget, _ = strconv.Atoi(r.Form.Get("gamblingSessionBudget"))
+		cfg.Gambling.StopLoss, _ = strconv.Atoi(r.Form.Get("gamblingStopLoss"))
 
 		// Cube Recipes
 		cfg.CubeRecipes.Enabled = r.Form.Has("enableCubeRecipes")
@@ -968,13 +998,22 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		cfg.CubeRecipes.EnabledRecipes = enabledRecipes
 		cfg.CubeRecipes.SkipPerfectAmethysts = r.Form.Has("skipPerfectAmethysts")
 		cfg.CubeRecipes.SkipPerfectRubies = r.Form.Has("skipPerfectRubies")
+		cfg.CubeRecipes.Crafter = r.Form.Has("cubeRecipesCrafter")
 		// Companion
 
 		// Companion config
+		cfg.Companion.Enabled = r.Form.Has("companionEnabled")
 		cfg.Companion.Leader = r.Form.Has("companionLeader")
+		cfg.Companion.FollowLeader = r.Form.Has("companionFollowLeader")
+		cfg.Companion.Attack = r.Form.Has("companionAttack")
+		cfg.Companion.FollowDistance, _ = strconv.Atoi(r.Form.Get("companionFollowDistance"))
+		cfg.Companion.BuffInterval, _ = strconv.Atoi(r.Form.Get("companionBuffInterval"))
 		cfg.Companion.LeaderName = r.Form.Get("companionLeaderName")
+		cfg.Companion.LeaderAddress = r.Form.Get("companionLeaderAddress")
 		cfg.Companion.GameNameTemplate = r.Form.Get("companionGameNameTemplate")
 		cfg.Companion.GamePassword = r.Form.Get("companionGamePassword")
+		cfg.Companion.GameNameStrategy = config.GameNameStrategy(r.Form.Get("companionGameNameStrategy"))
+		cfg.Companion.GamePasswordStrategy = config.GamePasswordStrategy(r.Form.Get("companionGamePasswordStrategy"))
 
 		// Back to town config
 		cfg.BackToTown.NoHpPotions = r.Form.Has("noHpPotions")
@@ -1022,6 +1061,12 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 
 	dayNames := []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
 
+	// User defined recipes from cube_recipes.yaml are listed after the built-in ones
+	recipeList := slices.Clone(config.AvailableRecipes)
+	for _, recipe := range cfg.Runtime.CubeRecipes {
+		recipeList = append(recipeList, recipe.Name)
+	}
+
 	s.templates.ExecuteTemplate(w, "character_settings.gohtml", CharacterSettings{
 		Supervisor:   supervisor,
 		Config:       cfg,
@@ -1029,6 +1074,6 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		EnabledRuns:  enabledRuns,
 		DisabledRuns: disabledRuns,
 		AvailableTZs: availableTZs,
-		RecipeList:   config.AvailableRecipes,
+		RecipeList:   recipeList,
 	})
 }
@@ -0,0 +1,181 @@
+package sharedstash
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+// Reservations are released after cubing, the expiration is only a safety net in case a supervisor is stopped
+// while it's holding items
+const reservationTTL = 10 * time.Minute
+
+// slot identifies an item in the shared stash, unit ids are different on every game but the position is kept
+type slot struct {
+	page int
+	x    int
+	y    int
+}
+
+type reservation struct {
+	supervisor string
+	expiresAt  time.Time
+}
+
+type pool struct {
+	ingredients  []data.Item
+	updatedBy    string
+	reservations map[slot]reservation
+}
+
+var mu sync.Mutex
+var pools = make(map[string]*pool)
+
+func slotOf(i data.Item) slot {
+	return slot{page: i.Location.Page, x: i.Position.X, y: i.Position.Y}
+}
+
+func getPool(account string) *pool {
+	p, found := pools[account]
+	if !found {
+		p = &pool{reservations: make(map[slot]reservation)}
+		pools[account] = p
+	}
+
+	return p
+}
+
+// Crafter returns the name of the character cubing the shared stash recipes for the given account, empty if none
+// of the characters is configured as crafter
+func Crafter(account string) string {
+	if account == "" {
+		return ""
+	}
+
+	names := make([]string, 0)
+	for name, cfg := range config.Characters {
+		if cfg.Username == account && cfg.CubeRecipes.Enabled && cfg.CubeRecipes.Crafter {
+			names = append(names, name)
+		}
+	}
+
+	// Map order is random, if more than one character is configured as crafter always pick the same one
+	slices.Sort(names)
+	if len(names) == 0 {
+		return ""
+	}
+
+	return names[0]
+}
+
+// HasOtherCrafter returns true if another character of the account is doing the cubing for the supervisor
+func HasOtherCrafter(account, supervisor string) bool {
+	crafter := Crafter(account)
+
+	return crafter != "" && crafter != supervisor
+}
+
+// Update refreshes the recipe ingredients found in the shared stash, reservations of items that are no longer
+// there are released
+func Update(account, supervisor string, ingredients []data.Item) {
+	if account == "" {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	p := getPool(account)
+	p.ingredients = slices.Clone(ingredients)
+	p.updatedBy = supervisor
+
+	for s, r := range p.reservations {
+		if !slices.ContainsFunc(ingredients, func(i data.Item) bool { return slotOf(i) == s }) || time.Now().After(r.expiresAt) {
+			delete(p.reservations, s)
+		}
+	}
+}
+
+// Ingredients returns the last known recipe ingredients in the shared stash and the supervisor that saw them
+func Ingredients(account string) ([]data.Item, string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	p, found := pools[account]
+	if !found {
+		return nil, ""
+	}
+
+	return slices.Clone(p.ingredients), p.updatedBy
+}
+
+// IsReserved returns true if the item is reserved by a different supervisor
+func IsReserved(account, supervisor string, i data.Item) bool {
+	if account == "" || i.Location.LocationType != item.LocationSharedStash {
+		return false
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	p, found := pools[account]
+	if !found {
+		return false
+	}
+
+	r, found := p.reservations[slotOf(i)]
+
+	return found && r.supervisor != supervisor && time.Now().Before(r.expiresAt)
+}
+
+// Reserve marks the shared stash items as in use by the supervisor, nothing is reserved if any of them is already
+// reserved by someone else. Items from the personal stash or inventory are ignored.
+func Reserve(account, supervisor string, items []data.Item) bool {
+	if account == "" {
+		return true
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	p := getPool(account)
+	now := time.Now()
+	for _, i := range items {
+		if i.Location.LocationType != item.LocationSharedStash {
+			continue
+		}
+		if r, found := p.reservations[slotOf(i)]; found && r.supervisor != supervisor && now.Before(r.expiresAt) {
+			return false
+		}
+	}
+
+	for _, i := range items {
+		if i.Location.LocationType != item.LocationSharedStash {
+			continue
+		}
+		p.reservations[slotOf(i)] = reservation{supervisor: supervisor, expiresAt: now.Add(reservationTTL)}
+	}
+
+	return true
+}
+
+// Release removes all the reservations of the supervisor
+func Release(account, supervisor string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	p, found := pools[account]
+	if !found {
+		return
+	}
+
+	for s, r := range p.reservations {
+		if r.supervisor == supervisor {
+			delete(p.reservations, s)
+		}
+	}
+}
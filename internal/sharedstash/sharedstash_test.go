@@ -0,0 +1,147 @@
+package sharedstash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+func sharedItem(page, x, y int) data.Item {
+	return data.Item{
+		Name:     "PerfectAmethyst",
+		Location: item.Location{LocationType: item.LocationSharedStash, Page: page},
+		Position: data.Position{X: x, Y: y},
+	}
+}
+
+func crafterCfg(account string, crafter bool) *config.CharacterCfg {
+	cfg := &config.CharacterCfg{}
+	cfg.Username = account
+	cfg.CubeRecipes.Enabled = true
+	cfg.CubeRecipes.Crafter = crafter
+
+	return cfg
+}
+
+func TestCrafter(t *testing.T) {
+	old := config.Characters
+	t.Cleanup(func() { config.Characters = old })
+
+	config.Characters = map[string]*config.CharacterCfg{
+		"mule":     crafterCfg("account", false),
+		"zcrafter": crafterCfg("account", true),
+		"acrafter": crafterCfg("account", true),
+		"other":    crafterCfg("other", true),
+	}
+
+	if got := Crafter("account"); got != "acrafter" {
+		t.Errorf("Crafter() = %q, want the first crafter sorted by name", got)
+	}
+	if got := Crafter(""); got != "" {
+		t.Errorf("Crafter() without account = %q, want empty", got)
+	}
+	if got := Crafter("unknown"); got != "" {
+		t.Errorf("Crafter() without crafters = %q, want empty", got)
+	}
+
+	if !HasOtherCrafter("account", "mule") {
+		t.Error("mule should have another crafter")
+	}
+	if HasOtherCrafter("account", "acrafter") {
+		t.Error("crafter should not have another crafter")
+	}
+	if HasOtherCrafter("unknown", "mule") {
+		t.Error("account without crafters should not have another crafter")
+	}
+}
+
+func TestReserve(t *testing.T) {
+	const account = "TestReserve"
+	gem := sharedItem(1, 2, 3)
+	personal := data.Item{Location: item.Location{LocationType: item.LocationStash}}
+
+	if !Reserve(account, "first", []data.Item{gem, personal}) {
+		t.Fatal("first reservation should succeed")
+	}
+	if !Reserve(account, "first", []data.Item{gem}) {
+		t.Error("supervisor should be able to reserve its own items again")
+	}
+	if Reserve(account, "second", []data.Item{sharedItem(1, 0, 0), gem}) {
+		t.Error("reservation of an item reserved by another supervisor should fail")
+	}
+	if IsReserved(account, "first", sharedItem(1, 0, 0)) || IsReserved(account, "second", sharedItem(1, 0, 0)) {
+		t.Error("failed reservation should not reserve any item")
+	}
+
+	if IsReserved(account, "first", gem) {
+		t.Error("item should not be reserved for the supervisor holding it")
+	}
+	if !IsReserved(account, "second", gem) {
+		t.Error("item should be reserved for other supervisors")
+	}
+	if IsReserved(account, "second", personal) {
+		t.Error("personal stash items can not be reserved")
+	}
+	if !Reserve("", "second", []data.Item{gem}) || IsReserved("", "first", gem) {
+		t.Error("characters without account should never be blocked")
+	}
+
+	Release(account, "first")
+	if IsReserved(account, "second", gem) {
+		t.Error("item should not be reserved after releasing it")
+	}
+	if !Reserve(account, "second", []data.Item{gem}) {
+		t.Error("released item should be reservable")
+	}
+}
+
+func TestReservationExpires(t *testing.T) {
+	const account = "TestReservationExpires"
+	gem := sharedItem(1, 2, 3)
+
+	Reserve(account, "first", []data.Item{gem})
+
+	mu.Lock()
+	pools[account].reservations[slotOf(gem)] = reservation{supervisor: "first", expiresAt: time.Now().Add(-time.Second)}
+	mu.Unlock()
+
+	if IsReserved(account, "second", gem) {
+		t.Error("expired reservation should be ignored")
+	}
+	if !Reserve(account, "second", []data.Item{gem}) {
+		t.Error("expired reservation should be replaced")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	const account = "TestUpdate"
+	kept := sharedItem(1, 0, 0)
+	used := sharedItem(1, 5, 5)
+
+	Reserve(account, "first", []data.Item{kept, used})
+	Update(account, "second", []data.Item{kept})
+
+	ingredients, updatedBy := Ingredients(account)
+	if len(ingredients) != 1 || updatedBy != "second" {
+		t.Fatalf("Ingredients() = %v, %q; want the updated ingredients and supervisor", ingredients, updatedBy)
+	}
+	if !IsReserved(account, "second", kept) {
+		t.Error("reservation of an item still in the stash should be kept")
+	}
+	if IsReserved(account, "second", used) {
+		t.Error("reservation of an item no longer in the stash should be released")
+	}
+
+	// Returned ingredients are a copy
+	ingredients[0].Name = "Changed"
+	if ingredients, _ = Ingredients(account); ingredients[0].Name != kept.Name {
+		t.Error("Ingredients() should not expose the pool")
+	}
+
+	if ingredients, updatedBy = Ingredients("unknown"); ingredients != nil || updatedBy != "" {
+		t.Error("unknown account should not have ingredients")
+	}
+}
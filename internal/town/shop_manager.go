@@ -181,6 +181,10 @@ func ItemsToBeSold() (items []data.Item) {
 			if _, result := ctx.Data.CharacterCfg.Runtime.Rules.EvaluateAll(itm); result == nip.RuleResultFullMatch && !itm.IsPotion() {
 				continue
 			}
+			// Same for items bought from vendors
+			if _, result := ctx.Data.CharacterCfg.Runtime.ShoppingRules.EvaluateAll(itm); result == nip.RuleResultFullMatch {
+				continue
+			}
 			items = append(items, itm)
 		}
 	}